@@ -0,0 +1,169 @@
+package bruno2openapi
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// assertion is one enabled line of an assert block, e.g.
+// res.body.id: eq 42 is {expr: "res.body.id", op: "eq", value: "42"}.
+type assertion struct {
+	expr  string
+	op    string
+	value string
+}
+
+// assertions returns the enabled asserts of req sorted by expression.
+func assertions(req Request) []assertion {
+	list := []assertion{}
+	for _, expr := range sortedKeys(req.Asserts) {
+		if strings.HasPrefix(expr, "~") {
+			continue
+		}
+		op, value, _ := strings.Cut(strings.TrimSpace(req.Asserts[expr]), " ")
+		list = append(list, assertion{expr: expr, op: op, value: strings.TrimSpace(value)})
+	}
+	return list
+}
+
+// assertedStatus returns the status code a res.status: eq N assert expects.
+func assertedStatus(req Request) (int, bool) {
+	for _, a := range assertions(req) {
+		if a.expr == "res.status" && a.op == "eq" {
+			return parseStatusCode(a.value)
+		}
+	}
+	return 0, false
+}
+
+// assertTypes maps assert operators to the JSON type they imply.
+var assertTypes = map[string]string{
+	"isString":    "string",
+	"matches":     "string",
+	"notMatches":  "string",
+	"startsWith":  "string",
+	"endsWith":    "string",
+	"isNumber":    "number",
+	"gt":          "number",
+	"gte":         "number",
+	"lt":          "number",
+	"lte":         "number",
+	"between":     "number",
+	"isBoolean":   "boolean",
+	"isArray":     "array",
+	"length":      "array",
+	"isJson":      "object",
+	"isEmpty":     "",
+	"isDefined":   "",
+	"isTruthy":    "",
+	"isFalsy":     "",
+	"isNull":      "",
+	"isUndefined": "",
+}
+
+// bodyPathRegex splits a res.body path into .name, [0] and ['name'] parts.
+var bodyPathRegex = regexp.MustCompile(`\.([A-Za-z_$][\w$]*)|\[\s*(\d+)\s*\]|\[\s*['"]([^'"]+)['"]\s*\]`)
+
+// assertResponseSchema builds a minimal JSON schema for the response body
+// from res.body asserts: each asserted path becomes a (nested) property
+// whose type follows from the operator, with eq values as examples.
+// It returns nil when no assert inspects the body.
+func assertResponseSchema(req Request) *MediaSchema {
+	var root *MediaSchema
+	for _, a := range assertions(req) {
+		if a.expr != "res.body" && !strings.HasPrefix(a.expr, "res.body.") && !strings.HasPrefix(a.expr, "res.body[") {
+			continue
+		}
+		typ, example, ok := assertedType(a)
+		if !ok {
+			continue
+		}
+		if root == nil {
+			root = &MediaSchema{}
+		}
+		node := root
+		for _, m := range bodyPathRegex.FindAllStringSubmatch(strings.TrimPrefix(a.expr, "res.body"), -1) {
+			if m[2] != "" {
+				node.Type = "array"
+				if node.Items == nil {
+					node.Items = &MediaSchema{}
+				}
+				node = node.Items
+				continue
+			}
+			name := m[1] + m[3]
+			node.Type = "object"
+			if node.Properties == nil {
+				node.Properties = map[string]*MediaSchema{}
+			}
+			if node.Properties[name] == nil {
+				node.Properties[name] = &MediaSchema{}
+			}
+			node = node.Properties[name]
+		}
+		if node.Type == "" {
+			node.Type = typ
+		}
+		if example != nil {
+			node.Example = example
+		}
+	}
+	if root != nil {
+		completeArrays(root)
+	}
+	return root
+}
+
+// completeArrays gives arrays asserted without an element path the empty
+// items schema OpenAPI 3.0 requires.
+func completeArrays(s *MediaSchema) {
+	if s.Type == "array" && s.Items == nil {
+		s.Items = &MediaSchema{}
+	}
+	if s.Items != nil {
+		completeArrays(s.Items)
+	}
+	for _, prop := range s.Properties {
+		completeArrays(prop)
+	}
+}
+
+// assertedType derives the JSON type, and for eq the example value, that
+// an assertion implies. ok is false for operators it does not understand.
+func assertedType(a assertion) (typ string, example any, ok bool) {
+	if a.op == "eq" {
+		value := literalValue(a.value)
+		switch value.(type) {
+		case float64:
+			return "number", value, true
+		case bool:
+			return "boolean", value, true
+		case string:
+			return "string", value, true
+		}
+		return "", nil, true
+	}
+	typ, ok = assertTypes[a.op]
+	return typ, nil, ok
+}
+
+// literalValue parses an assert operand: numbers, booleans, null and
+// quoted or bare strings.
+func literalValue(raw string) any {
+	switch raw {
+	case "true":
+		return true
+	case "false":
+		return false
+	case "null", "":
+		return nil
+	}
+	if n, err := strconv.ParseFloat(raw, 64); err == nil {
+		return n
+	}
+	if unquoted, err := strconv.Unquote(raw); err == nil {
+		return unquoted
+	}
+	return strings.Trim(raw, `'`)
+}
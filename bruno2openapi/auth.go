@@ -0,0 +1,131 @@
+package bruno2openapi
+
+import "strings"
+
+// WarnMissingAuthBlock is reported when a request names an auth mode but
+// has no auth:<mode> block to document it from.
+const WarnMissingAuthBlock = "missing-auth-block"
+
+// authNone reports whether req opts out of the collection's security:
+// auth: none, or inherit where the nearest folder with auth is set to
+// none.
+func authNone(req Request) bool {
+	switch req.AuthMode {
+	case "none":
+		return true
+	case "inherit":
+		for _, f := range req.Folders {
+			if f.AuthMode == "none" {
+				return true
+			}
+			if f.Auth != nil {
+				return false
+			}
+		}
+	}
+	return false
+}
+
+// securityScheme translates a Bruno auth block into the security scheme
+// it documents and the scopes the operation requires. ok is false for
+// modes without an OpenAPI equivalent.
+func securityScheme(auth *Auth) (scheme SecurityScheme, scopes []string, ok bool) {
+	if auth == nil {
+		return SecurityScheme{}, nil, false
+	}
+	switch auth.Mode {
+	case "bearer":
+		return SecurityScheme{Type: "http", Scheme: "bearer"}, []string{}, true
+	case "basic":
+		return SecurityScheme{Type: "http", Scheme: "basic"}, []string{}, true
+	case "apikey":
+		name := auth.Values["key"]
+		if name == "" {
+			return SecurityScheme{}, nil, false
+		}
+		in := "header"
+		// Bruno writes query placement as "queryparams".
+		if strings.HasPrefix(strings.ToLower(auth.Values["placement"]), "query") {
+			in = "query"
+		}
+		return SecurityScheme{Type: "apiKey", Name: name, In: in}, []string{}, true
+	case "oauth2":
+		return oauth2Scheme(auth.Values)
+	}
+	return SecurityScheme{}, nil, false
+}
+
+// oauth2Scheme builds the flow for the block's grant_type. The requested
+// scopes become both the flow's scope list and the operation's required
+// scopes.
+func oauth2Scheme(values map[string]string) (SecurityScheme, []string, bool) {
+	scopes := []string{}
+	scopeMap := map[string]string{}
+	for _, scope := range strings.FieldsFunc(values["scope"], func(r rune) bool { return r == ' ' || r == ',' }) {
+		if _, dup := scopeMap[scope]; !dup {
+			scopes = append(scopes, scope)
+			scopeMap[scope] = ""
+		}
+	}
+	flow := &OAuthFlow{
+		AuthorizationURL: values["authorization_url"],
+		TokenURL:         firstNonEmpty(values["access_token_url"], values["token_url"]),
+		RefreshURL:       values["refresh_token_url"],
+		Scopes:           scopeMap,
+	}
+	flows := &OAuthFlows{}
+	switch strings.ToLower(values["grant_type"]) {
+	case "authorization_code":
+		flows.AuthorizationCode = flow
+	case "client_credentials":
+		flows.ClientCredentials = flow
+	case "password":
+		flow.AuthorizationURL = ""
+		flows.Password = flow
+	case "implicit":
+		flow.TokenURL = ""
+		flows.Implicit = flow
+	default:
+		return SecurityScheme{}, nil, false
+	}
+	return SecurityScheme{Type: "oauth2", Flows: flows}, scopes, true
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// awsSigV4 returns the x-aws-sigv4 extension for an auth:awsv4 block.
+// SigV4 has no OpenAPI security scheme, so only the signing service and
+// region are documented; credentials never are.
+func awsSigV4(auth *Auth) map[string]string {
+	if auth == nil || auth.Mode != "awsv4" {
+		return nil
+	}
+	sigv4 := map[string]string{}
+	for _, key := range []string{"service", "region"} {
+		if v := auth.Values[key]; v != "" {
+			sigv4[key] = v
+		}
+	}
+	return sigv4
+}
+
+func awsSigV4Note(sigv4 map[string]string) string {
+	details := []string{}
+	for _, key := range []string{"service", "region"} {
+		if v := sigv4[key]; v != "" {
+			details = append(details, key+" "+v)
+		}
+	}
+	note := "Requires AWS Signature Version 4 authentication"
+	if len(details) > 0 {
+		note += " (" + strings.Join(details, ", ") + ")"
+	}
+	return note + "."
+}
@@ -0,0 +1,92 @@
+package bruno2openapi
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var syntheticMethods = []string{"get", "post", "put", "patch", "delete"}
+
+// generateSyntheticCollection writes n .bru requests spread over nested
+// folders under dir, exercising path params, query params, headers, docs
+// and JSON bodies. It returns the raw file contents in write order.
+func generateSyntheticCollection(tb testing.TB, dir string, n int) []string {
+	tb.Helper()
+	contents := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		method := syntheticMethods[i%len(syntheticMethods)]
+		folder := filepath.Join(dir, fmt.Sprintf("area%02d", i%20), fmt.Sprintf("resource%02d", i%7))
+		if err := os.MkdirAll(folder, 0755); err != nil {
+			tb.Fatal(err)
+		}
+
+		content := fmt.Sprintf(`meta {
+  name: Request %[1]d
+  type: http
+  seq: %[1]d
+}
+
+%[2]s {
+  url: {{baseUrl}}/api/v1/area%[3]d/items/:itemId/sub%[1]d?page=1&size=%[1]d
+  body: json
+  auth: none
+}
+
+params:path {
+  itemId: %[1]d
+}
+
+headers {
+  X-Request-Id: req-%[1]d
+  Content-Type: application/json
+}
+
+docs {
+  Synthetic request number %[1]d.
+}
+`, i, method, i%20)
+		if method != "get" && method != "delete" {
+			content += fmt.Sprintf(`
+body:json {
+  {
+    "id": %[1]d,
+    "name": "item %[1]d",
+    "tags": ["a", "b"],
+    "nested": {"enabled": true, "ratio": 0.5}
+  }
+}
+`, i)
+		}
+
+		if err := os.WriteFile(filepath.Join(folder, fmt.Sprintf("request%04d.bru", i)), []byte(content), 0644); err != nil {
+			tb.Fatal(err)
+		}
+		contents = append(contents, content)
+	}
+	return contents
+}
+
+func BenchmarkParseBru(b *testing.B) {
+	contents := generateSyntheticCollection(b, b.TempDir(), 1000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, content := range contents {
+			parseBru(content)
+		}
+	}
+}
+
+func BenchmarkBuildOpenAPI(b *testing.B) {
+	dir := b.TempDir()
+	generateSyntheticCollection(b, dir, 1000)
+	requests, err := CollectRequests(os.DirFS(dir), ".")
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		buildOpenAPI(requests, Options{})
+	}
+}
@@ -0,0 +1,1154 @@
+package bruno2openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Build converts parsed requests into an OpenAPI document.
+func Build(requests []Request, opts Options) (OpenAPI, error) {
+	if err := opts.Validate(); err != nil {
+		return OpenAPI{}, err
+	}
+	doc := buildOpenAPI(requests, opts)
+	if opts.OnConflict == OnConflictError {
+		duplicates := []string{}
+		for _, w := range doc.Warnings {
+			if w.Code == WarnDuplicateOperation {
+				duplicates = append(duplicates, fmt.Sprintf("%s (%s)", w.Key, w.File))
+			}
+		}
+		if len(duplicates) > 0 {
+			return OpenAPI{}, fmt.Errorf("%d duplicate operation(s): %s", len(duplicates), strings.Join(duplicates, ", "))
+		}
+	}
+	if opts.Unresolved == UnresolvedError {
+		unresolved := []string{}
+		for _, w := range doc.Warnings {
+			if w.Code == WarnUnresolvedVariable {
+				unresolved = append(unresolved, fmt.Sprintf("{{%s}} in %s", w.Key, w.File))
+			}
+		}
+		if len(unresolved) > 0 {
+			return OpenAPI{}, fmt.Errorf("%d unresolved variable(s): %s", len(unresolved), strings.Join(unresolved, ", "))
+		}
+	}
+	return doc, nil
+}
+
+// builder accumulates the document while requests are converted.
+type builder struct {
+	opts     Options
+	opIDs    *operationIDs
+	paths    map[string]*PathItem
+	sources  map[string]map[string]string
+	tagSet   map[string]bool
+	tagDescs map[string]string
+	tagDocs  map[string]*ExternalDocs
+	security *schemeRegistry
+	// globalSecurity is the requirement of the collection-level auth;
+	// operations with the same requirement leave it to the document.
+	globalSecurity SecurityRequirement
+	warnings       []Warning
+	// pathOrder and tagOrder record first appearances for OrderBySeq.
+	pathOrder []string
+	tagOrder  []string
+	// tagGroups lists the folder tags of each top-level folder for
+	// TagStrategyGroups, in first appearance; tagGroupOf maps them back.
+	tagGroups  map[string][]string
+	tagGroupOf map[string]string
+	groupOrder []string
+}
+
+func buildOpenAPI(requests []Request, opts Options) OpenAPI {
+	opIDs, err := newOperationIDs(opts.OperationIDStyle, opts.OperationIDStrategy)
+	if err != nil {
+		// Options.Validate rejects unknown styles and strategies
+		// before getting here.
+		opIDs, _ = newOperationIDs(OperationIDCamel, OperationIDRequestName)
+	}
+	b := &builder{
+		opts:     opts,
+		opIDs:    opIDs,
+		paths:    map[string]*PathItem{},
+		sources:  map[string]map[string]string{},
+		tagSet:   map[string]bool{},
+		tagDescs: map[string]string{},
+		tagDocs:  map[string]*ExternalDocs{},
+		security: newSchemeRegistry(),
+
+		tagGroups:  map[string][]string{},
+		tagGroupOf: map[string]string{},
+	}
+	if scheme, scopes, ok := securityScheme(collectionAuth(requests)); ok {
+		name := b.security.register(scheme, collectionFile)
+		b.globalSecurity = SecurityRequirement{name: scopes}
+	}
+	serverCount := map[string]int{}
+	templateCount := map[string]int{}
+	probes := []probeRequest{}
+	if opts.Ordering == OrderBySeq {
+		requests = sortBySeq(requests)
+	}
+
+	extraMethods := map[string]bool{}
+	for _, method := range opts.ExtraMethods {
+		extraMethods[strings.ToLower(strings.TrimSpace(method))] = true
+	}
+	for _, req := range requests {
+		if req.Excluded() {
+			continue
+		}
+		if !openAPIMethods[req.Method] && !extraMethods[req.Method] {
+			b.warnings = append(b.warnings, Warning{
+				Code:    WarnUnsupportedMethod,
+				File:    req.File,
+				Key:     strings.ToUpper(req.Method),
+				Message: "not an OpenAPI method; list it in the extra methods to document it as an x- extension",
+			})
+			continue
+		}
+		pathName, server := resolveURL(req.URL, opts)
+		pathName = normalizeSlashes(pathName, opts.TrailingSlash)
+		normalizedPath := normalizePathParams(templatePathVariables(pathName))
+		headers, warnings := canonicalHeaders(req.Headers, req.File)
+		req.Headers = headers
+		b.warnings = append(b.warnings, warnings...)
+		vars := opts.Variables.With(req.Vars)
+		req = b.applyUnresolved(resolveExamples(req, vars), vars)
+
+		if server != "" {
+			serverCount[server]++
+		}
+		if _, template := splitURL(req.URL); template != "" {
+			templateCount[template]++
+		} else if template = opts.baseURL(); template != "" {
+			templateCount[template]++
+		}
+		if opts.FoldProbeMethods && isProbeMethod(req.Method) {
+			probes = append(probes, probeRequest{path: normalizedPath, server: server, req: req})
+			continue
+		}
+		b.addOperation(normalizedPath, server, req)
+	}
+	for _, probe := range probes {
+		b.foldProbe(probe)
+	}
+
+	// The host most requests use is the document's server; operations on
+	// other hosts list their own.
+	servers := []Server{}
+	dominant := ""
+	for _, url := range sortedKeys(serverCount) {
+		if serverCount[url] > serverCount[dominant] {
+			dominant = url
+		}
+	}
+	if dominant != "" {
+		servers = append(servers, Server{URL: dominant})
+	}
+	if len(opts.Environments) > 0 {
+		template := ""
+		for _, t := range sortedKeys(templateCount) {
+			if templateCount[t] > templateCount[template] {
+				template = t
+			}
+		}
+		if envServers := environmentServers(template, opts.Environments); len(envServers) > 0 {
+			servers = envServers
+		}
+	}
+	for _, item := range b.paths {
+		for method, op := range item.Operations {
+			if len(op.Servers) == 1 && op.Servers[0].URL == dominant {
+				op.Servers = nil
+				item.Operations[method] = op
+			}
+		}
+	}
+
+	tagNames := sortedKeys(b.tagSet)
+	if opts.Ordering == OrderBySeq {
+		tagNames = b.tagOrder
+	}
+	tags := []Tag{}
+	for _, name := range tagNames {
+		tags = append(tags, Tag{Name: name, Description: b.tagDescs[name], ExternalDocs: b.tagDocs[name]})
+	}
+
+	openapi := OpenAPI{
+		OpenAPI: "3.0.0",
+		Info: Info{
+			Title:   DefaultTitle,
+			Version: DefaultVersion,
+		},
+		Paths:    b.paths,
+		Sources:  b.sources,
+		Warnings: append(b.warnings, b.security.warnings...),
+	}
+	if opts.Ordering == OrderBySeq {
+		openapi.PathOrder = b.pathOrder
+	}
+	if len(b.security.schemes) > 0 {
+		openapi.Components = &Components{SecuritySchemes: b.security.schemes}
+	}
+	if b.globalSecurity != nil {
+		openapi.Security = []SecurityRequirement{b.globalSecurity}
+	}
+	if opts.TagStrategy == TagStrategyGroups && len(tagNames) > 0 {
+		openapi.Extensions = map[string]any{"x-tagGroups": b.tagGroupList(tagNames)}
+	}
+	for _, item := range b.paths {
+		for _, op := range item.Operations {
+			if op.RequestBody != nil {
+				for _, media := range op.RequestBody.Content {
+					applyRequiredProperties(media.Schema, opts.RequiredProperties)
+				}
+			}
+			for _, resp := range op.Responses {
+				for _, media := range resp.Content {
+					applyRequiredProperties(media.Schema, opts.RequiredProperties)
+				}
+			}
+		}
+	}
+	openapi.Components = shareSchemas(b.paths, openapi.Components)
+	if opts.Title != "" {
+		openapi.Info.Title = opts.Title
+	}
+	if opts.Version != "" {
+		openapi.Info.Version = opts.Version
+	}
+	openapi.Info.Description = opts.Description
+	openapi.Info.TermsOfService = opts.TermsOfService
+	if c := opts.Contact; c != nil && (c.Name != "" || c.URL != "" || c.Email != "") {
+		openapi.Info.Contact = c
+	}
+	if l := opts.License; l != nil && l.Name != "" {
+		openapi.Info.License = l
+	}
+	if collection, ok := collectionFolder(requests); ok {
+		if openapi.Info.Description == "" {
+			openapi.Info.Description = collection.Description
+		}
+		openapi.ExternalDocs = collection.ExternalDocs
+	}
+	if len(servers) > 0 {
+		openapi.Servers = servers
+	}
+	if len(tags) > 0 {
+		openapi.Tags = tags
+	}
+	return openapi
+}
+
+// resolveExamples returns a copy of req whose parameter values, headers
+// and bodies have their variables resolved for use as examples.
+func resolveExamples(req Request, vars *Variables) Request {
+	if vars == nil {
+		return req
+	}
+	resolve := func(m map[string]string) map[string]string {
+		out := make(map[string]string, len(m))
+		for k, v := range m {
+			// A parameter set to a variable whose value depends on the
+			// environment keeps its placeholder; applyEnvironmentValues
+			// documents all values instead of baking one in.
+			if name, ok := placeholderName(v); ok && !vars.IsSecret(name) {
+				if envs, _ := vars.EnvironmentValues(name); envs != nil {
+					out[k] = v
+					continue
+				}
+			}
+			out[k] = vars.Example(v)
+		}
+		return out
+	}
+	req.Query = resolve(req.Query)
+	req.PathParams = resolve(req.PathParams)
+	req.Headers = resolve(req.Headers)
+	req.Body = vars.Example(req.Body)
+	req.GraphQLVars = vars.Example(req.GraphQLVars)
+	return req
+}
+
+// applyUnresolved handles the placeholders resolveExamples left in req
+// according to opts.Unresolved. Folder variables, environment-dependent
+// values and Bruno's dynamic {{$...}} variables do not count: they are
+// documented as parameter defaults or generated at run time.
+func (b *builder) applyUnresolved(req Request, vars *Variables) Request {
+	mode := b.opts.Unresolved
+	if mode == "" || mode == UnresolvedKeep {
+		return req
+	}
+	known := map[string]bool{}
+	for _, v := range req.FolderVars {
+		known[v.Name] = true
+	}
+	unresolved := map[string]bool{}
+	fix := func(s string) string {
+		return placeholderRegex.ReplaceAllStringFunc(s, func(match string) string {
+			name := placeholderRegex.FindStringSubmatch(match)[1]
+			if envs, _ := vars.EnvironmentValues(name); envs != nil || known[name] || strings.HasPrefix(name, "$") {
+				return match
+			}
+			unresolved[name] = true
+			if mode == UnresolvedBlank {
+				return ""
+			}
+			return match
+		})
+	}
+	fixAll := func(m map[string]string) map[string]string {
+		out := make(map[string]string, len(m))
+		for k, v := range m {
+			out[k] = fix(v)
+		}
+		return out
+	}
+	req.Query = fixAll(req.Query)
+	req.PathParams = fixAll(req.PathParams)
+	req.Headers = fixAll(req.Headers)
+	req.Body = fix(req.Body)
+	req.GraphQLVars = fix(req.GraphQLVars)
+	if mode == UnresolvedError {
+		for _, name := range sortedKeys(unresolved) {
+			b.warnings = append(b.warnings, Warning{
+				Code:    WarnUnresolvedVariable,
+				File:    req.File,
+				Key:     name,
+				Message: fmt.Sprintf("{{%s}} has no value in any environment or vars block", name),
+			})
+		}
+	}
+	return req
+}
+
+// pathItem returns the path item for pathName, creating it on first use.
+func (b *builder) pathItem(pathName string) *PathItem {
+	item, ok := b.paths[pathName]
+	if !ok {
+		item = &PathItem{Operations: map[string]Operation{}}
+		b.paths[pathName] = item
+		b.sources[pathName] = map[string]string{}
+		b.pathOrder = append(b.pathOrder, pathName)
+	}
+	return item
+}
+
+// addOperation converts req into the operation for its method on pathName.
+func (b *builder) addOperation(pathName, server string, req Request) {
+	parameters := []Parameter{}
+	for _, e := range keyValueEntries(req.Query, b.opts.IncludeDisabled) {
+		parameters = append(parameters, Parameter{
+			Name:        e.name,
+			In:          "query",
+			Description: e.description(),
+			Required:    false,
+			Schema:      Schema{Type: "string"},
+			Example:     e.value,
+		})
+	}
+	for _, e := range keyValueEntries(req.PathParams, b.opts.IncludeDisabled) {
+		parameters = append(parameters, Parameter{
+			Name:        e.name,
+			In:          "path",
+			Description: e.description(),
+			Required:    true,
+			Schema:      Schema{Type: "string"},
+			Example:     e.value,
+		})
+	}
+
+	for _, name := range extractPathParams(pathName) {
+		if !hasPathParam(parameters, name) {
+			parameters = append(parameters, Parameter{
+				Name:     name,
+				In:       "path",
+				Required: true,
+				Schema:   Schema{Type: "string"},
+			})
+		}
+	}
+	parameters = append(parameters, headerParameters(req, b.opts)...)
+	parameters = append(parameters, cookieParameters(req, b.opts)...)
+	applyFolderDefaults(parameters, req.FolderVars)
+	b.applyEnvironmentValues(parameters, req.File)
+
+	op := Operation{
+		OperationID:  b.opIDs.next(req, pathName),
+		Summary:      operationSummary(req, pathName),
+		Description:  req.Description,
+		Responses:    buildResponses(req),
+		ExternalDocs: req.ExternalDocs,
+	}
+	// The folder's docs describe its tag even when meta tags take over,
+	// for other operations, or the meta tags themselves, that use it.
+	tag := ""
+	if names, folder := folderTag(req, b.opts.TagDepth); len(names) > 0 {
+		tag = b.folderTagName(names)
+		if folder.Description != "" {
+			b.tagDescs[tag] = folder.Description
+		}
+		if folder.ExternalDocs != nil {
+			b.tagDocs[tag] = folder.ExternalDocs
+		}
+	}
+	if tags := metaTags(req); len(tags) > 0 {
+		op.Tags = tags
+	} else if tag != "" {
+		op.Tags = []string{tag}
+	}
+	for _, tag := range op.Tags {
+		if !b.tagSet[tag] {
+			b.tagOrder = append(b.tagOrder, tag)
+		}
+		b.tagSet[tag] = true
+	}
+	if len(parameters) > 0 {
+		op.Parameters = parameters
+	}
+	if rb := buildRequestBody(req, b.opts); rb != nil {
+		if b.opts.SkipUnexpectedBodies && !bodyAllowed(req.Method) {
+			b.warnings = append(b.warnings, Warning{
+				Code:    WarnBodyNotAllowed,
+				File:    req.File,
+				Key:     operationKey(req.Method, pathName),
+				Message: fmt.Sprintf("%s operations should not have a request body; body left out", strings.ToUpper(req.Method)),
+			})
+		} else {
+			rb.Required = bodyRequired(req, b.opts.BodyRequired)
+			op.RequestBody = rb
+		}
+	}
+	b.applyEnums(req, pathName, &op)
+	if !b.opts.NoTypeInference {
+		// After applyEnums: parameters with an enum stay strings.
+		inferQueryTypes(op.Parameters)
+	}
+	if scheme, scopes, ok := securityScheme(req.Auth); ok {
+		name := b.security.register(scheme, req.File)
+		if requirement := (SecurityRequirement{name: scopes}); !reflect.DeepEqual(requirement, b.globalSecurity) {
+			op.Security = SecurityRequirements{requirement}
+		}
+	} else if b.globalSecurity != nil && authNone(req) {
+		op.Security = SecurityRequirements{}
+	}
+	if req.Auth == nil && req.AuthMode != "" && req.AuthMode != "none" && req.AuthMode != "inherit" {
+		b.warnings = append(b.warnings, Warning{
+			Code:    WarnMissingAuthBlock,
+			File:    req.File,
+			Key:     "auth",
+			Message: fmt.Sprintf("auth mode %s has no auth:%s block; documented without its own security", req.AuthMode, req.AuthMode),
+		})
+	}
+	if op.Description == "" && b.opts.DescriptionTemplate != "" {
+		op.Description = renderDescription(b.opts.DescriptionTemplate, req, pathName, op.OperationID)
+		op.setExtension("x-generated-description", true)
+	}
+	if sigv4 := awsSigV4(req.Auth); sigv4 != nil {
+		op.setExtension("x-aws-sigv4", sigv4)
+		op.Description = strings.TrimSpace(op.Description + "\n\n" + awsSigV4Note(sigv4))
+	}
+	if b.opts.EmitScripts && len(req.Scripts) > 0 {
+		op.setExtension("x-bruno-scripts", req.Scripts)
+	}
+	if len(req.Settings) > 0 {
+		op.setExtension("x-bruno-settings", settingsExtension(req.Settings))
+	}
+	if req.IsDeprecated() {
+		op.Deprecated = true
+	}
+	if req.Sunset != "" {
+		op.Deprecated = true
+		op.setExtension("x-sunset", req.Sunset)
+	}
+	for key, raw := range req.Extensions {
+		op.setExtension(key, extensionValue(raw))
+	}
+
+	if server != "" {
+		op.Servers = []Server{{URL: server}}
+	}
+	if !openAPIMethods[req.Method] {
+		item := b.pathItem(pathName)
+		if item.Extensions == nil {
+			item.Extensions = map[string]any{}
+		}
+		item.Extensions["x-"+req.Method] = op
+		b.sources[pathName][req.Method] = req.File
+		return
+	}
+	pathName, op, ok := b.resolveConflict(pathName, req, op)
+	if !ok {
+		return
+	}
+	item := b.pathItem(pathName)
+	if _, exists := item.Operations[req.Method]; !exists && b.opts.Ordering == OrderBySeq {
+		item.Order = append(item.Order, req.Method)
+	}
+	item.Operations[req.Method] = op
+	b.sources[pathName][req.Method] = req.File
+}
+
+// sortBySeq orders requests the way the collection lists them: by folder,
+// then by meta seq, with unsequenced requests last and ties broken by file.
+func sortBySeq(requests []Request) []Request {
+	sorted := append([]Request(nil), requests...)
+	seq := func(r Request) int {
+		if r.Seq <= 0 {
+			return int(^uint(0) >> 1)
+		}
+		return r.Seq
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		di, dj := path.Dir(sorted[i].File), path.Dir(sorted[j].File)
+		if di != dj {
+			return di < dj
+		}
+		if seq(sorted[i]) != seq(sorted[j]) {
+			return seq(sorted[i]) < seq(sorted[j])
+		}
+		return sorted[i].File < sorted[j].File
+	})
+	return sorted
+}
+
+// metaTags returns the request's meta tags in order, without duplicates
+// and without the reserved IgnoreTag and DeprecatedTag. Explicit tags
+// take precedence over the folder-derived tag.
+func metaTags(req Request) []string {
+	tags := []string{}
+	seen := map[string]bool{}
+	for _, tag := range req.Tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || tag == IgnoreTag || tag == DeprecatedTag || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+	}
+	return tags
+}
+
+// folderTag names the request's tag after its folder, truncated to depth
+// segments, and returns the name of each segment, the folder.bru meta name
+// when there is one, with the folder whose docs describe the tag.
+func folderTag(req Request, depth int) ([]string, Folder) {
+	tagPath := truncateTag(req.Tag, depth)
+	if tagPath == "" {
+		return nil, Folder{}
+	}
+	byPath := map[string]Folder{}
+	for _, f := range req.Folders {
+		byPath[f.Path] = f
+	}
+	parts := strings.Split(tagPath, "/")
+	names := make([]string, len(parts))
+	for i, part := range parts {
+		names[i] = part
+		if f, ok := byPath[strings.Join(parts[:i+1], "/")]; ok && f.Name != "" {
+			names[i] = f.Name
+		}
+	}
+	return names, byPath[tagPath]
+}
+
+// folderTagName turns the segment names of a folder tag into the tag. With
+// TagStrategyGroups the tag is the leaf folder's name, or the full path
+// when another top-level folder already has a leaf of that name, and is
+// recorded in the group of its top-level folder.
+func (b *builder) folderTagName(names []string) string {
+	tag := strings.Join(names, "/")
+	if b.opts.TagStrategy != TagStrategyGroups {
+		return tag
+	}
+	group := names[0]
+	if leaf := names[len(names)-1]; b.tagGroupOf[leaf] == "" || b.tagGroupOf[leaf] == group {
+		tag = leaf
+	}
+	if _, ok := b.tagGroupOf[tag]; !ok {
+		if _, ok := b.tagGroups[group]; !ok {
+			b.groupOrder = append(b.groupOrder, group)
+		}
+		b.tagGroupOf[tag] = group
+		b.tagGroups[group] = append(b.tagGroups[group], tag)
+	}
+	return tag
+}
+
+// tagGroupList returns the x-tagGroups of TagStrategyGroups. Tags are
+// listed in the order of tagNames; tags that are not a folder's, such as
+// meta tags, go to a final OtherTagGroup group so that Redoc, which hides
+// ungrouped tags, still shows them.
+func (b *builder) tagGroupList(tagNames []string) []TagGroup {
+	groupNames := sortedKeys(b.tagGroups)
+	if b.opts.Ordering == OrderBySeq {
+		groupNames = b.groupOrder
+	}
+	rank := map[string]int{}
+	for i, name := range tagNames {
+		rank[name] = i
+	}
+	groups := []TagGroup{}
+	for _, name := range groupNames {
+		tags := append([]string{}, b.tagGroups[name]...)
+		sort.SliceStable(tags, func(i, j int) bool { return rank[tags[i]] < rank[tags[j]] })
+		groups = append(groups, TagGroup{Name: name, Tags: tags})
+	}
+	other := []string{}
+	for _, name := range tagNames {
+		if _, ok := b.tagGroupOf[name]; !ok {
+			other = append(other, name)
+		}
+	}
+	if len(other) > 0 {
+		groups = append(groups, TagGroup{Name: OtherTagGroup, Tags: other})
+	}
+	return groups
+}
+
+// truncateTag keeps the first depth segments of a slash-separated folder
+// tag; depth 0 keeps it whole.
+func truncateTag(tag string, depth int) string {
+	if depth <= 0 || tag == "" {
+		return tag
+	}
+	parts := strings.Split(tag, "/")
+	if len(parts) > depth {
+		parts = parts[:depth]
+	}
+	return strings.Join(parts, "/")
+}
+
+// successStatus picks the documented success response code. An explicit
+// meta status wins over the default; codes derived from asserts, and then
+// the first 2xx code checked by tests, take precedence over both.
+func successStatus(req Request) string {
+	if code, ok := assertedStatus(req); ok {
+		return strconv.Itoa(code)
+	}
+	for _, code := range testStatuses(req.Tests) {
+		if code >= 200 && code < 300 {
+			return strconv.Itoa(code)
+		}
+	}
+	if code, ok := parseStatusCode(req.Status); ok {
+		return strconv.Itoa(code)
+	}
+	return "200"
+}
+
+// parseStatusCode accepts a three-digit HTTP status code.
+func parseStatusCode(raw string) (int, bool) {
+	code, err := strconv.Atoi(strings.TrimSpace(raw))
+	if err != nil || code < 100 || code > 599 {
+		return 0, false
+	}
+	return code, true
+}
+
+// reservedHeaders must not be declared as header parameters: OpenAPI
+// describes them through the media types and security schemes instead.
+var reservedHeaders = map[string]bool{
+	"accept":        true,
+	"authorization": true,
+	"content-type":  true,
+}
+
+// hopByHopHeaders describe a single connection rather than the API, and
+// Host and Content-Length are set by the client, so none of them are
+// documented as parameters.
+var hopByHopHeaders = map[string]bool{
+	"connection":        true,
+	"keep-alive":        true,
+	"te":                true,
+	"trailer":           true,
+	"transfer-encoding": true,
+	"upgrade":           true,
+	"host":              true,
+	"content-length":    true,
+}
+
+// headerParameters documents the request's custom headers. Disabled
+// (~-prefixed) headers are skipped unless opts.IncludeDisabled is set;
+// reserved, hop-by-hop and Proxy-* headers and those in opts.HeaderIgnore
+// always are. Cookie is left to cookieParameters unless
+// opts.KeepCookieHeader is set.
+func headerParameters(req Request, opts Options) []Parameter {
+	ignored := map[string]bool{}
+	for _, name := range opts.HeaderIgnore {
+		ignored[strings.ToLower(strings.TrimSpace(name))] = true
+	}
+
+	parameters := []Parameter{}
+	for _, e := range keyValueEntries(req.Headers, opts.IncludeDisabled) {
+		lower := strings.ToLower(e.name)
+		if reservedHeaders[lower] || hopByHopHeaders[lower] || strings.HasPrefix(lower, "proxy-") || ignored[lower] {
+			continue
+		}
+		if lower == "cookie" && !opts.KeepCookieHeader {
+			continue
+		}
+		parameters = append(parameters, Parameter{
+			Name:        e.name,
+			In:          "header",
+			Description: e.description(),
+			Required:    false,
+			Schema:      Schema{Type: "string"},
+			Example:     e.value,
+		})
+	}
+	return parameters
+}
+
+// keyValueEntry is one line of a headers, query or params block.
+type keyValueEntry struct {
+	name     string
+	value    string
+	disabled bool
+}
+
+func (e keyValueEntry) description() string {
+	if e.disabled {
+		return "Disabled in the Bruno request."
+	}
+	return ""
+}
+
+// keyValueEntries returns the entries of a key/value block sorted by name.
+// Lines Bruno keeps but does not send carry a ~ prefix; they are dropped
+// unless includeDisabled is set, and then only when no enabled line of the
+// same name exists.
+func keyValueEntries(values map[string]string, includeDisabled bool) []keyValueEntry {
+	entries := []keyValueEntry{}
+	for _, key := range sortedKeys(values) {
+		name, disabled := strings.CutPrefix(key, "~")
+		if disabled {
+			if _, enabled := values[name]; enabled || !includeDisabled || name == "" {
+				continue
+			}
+		}
+		entries = append(entries, keyValueEntry{name: name, value: values[key], disabled: disabled})
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].name < entries[j].name })
+	return entries
+}
+
+// applyFolderDefaults uses folder-level vars as defaults for query and
+// path parameters of the same name (or whose value is just that
+// {{variable}}), so "try it out" sends the value the folder would have
+// supplied in Bruno. A description the request already gives is kept.
+func applyFolderDefaults(parameters []Parameter, vars []FolderVar) {
+	for i := range parameters {
+		if parameters[i].In != "query" && parameters[i].In != "path" {
+			continue
+		}
+		for _, v := range vars {
+			placeholder := parameters[i].Example == "{{"+v.Name+"}}"
+			if (v.Name != parameters[i].Name && !placeholder) || v.Value == "" {
+				continue
+			}
+			if placeholder {
+				parameters[i].Example = v.Value
+			}
+			parameters[i].Schema.Default = v.Value
+			if parameters[i].Description != "" {
+				break
+			}
+			if v.Folder == "." {
+				parameters[i].Description = fmt.Sprintf("Defaults to the %s collection variable.", v.Name)
+			} else {
+				parameters[i].Description = fmt.Sprintf("Defaults to the %s variable of folder %s.", v.Name, v.Folder)
+			}
+			break
+		}
+	}
+}
+
+// applyEnvironmentValues documents parameters set to a variable that
+// differs between environments as an enum of the observed values. The
+// selected environment's value becomes the default; without one there is
+// no default and a warning is reported.
+func (b *builder) applyEnvironmentValues(parameters []Parameter, file string) {
+	for i := range parameters {
+		example, _ := parameters[i].Example.(string)
+		name, ok := placeholderName(example)
+		if !ok || b.opts.Variables.IsSecret(name) {
+			continue
+		}
+		envs, selected := b.opts.Variables.EnvironmentValues(name)
+		if envs == nil {
+			continue
+		}
+		values := map[string]bool{}
+		provenance := []string{}
+		for _, env := range sortedKeys(envs) {
+			values[envs[env]] = true
+			provenance = append(provenance, fmt.Sprintf("%s=%s", env, envs[env]))
+		}
+		parameters[i].Schema.Enum = sortedKeys(values)
+		parameters[i].Schema.Default = nil
+		parameters[i].Example = nil
+		parameters[i].Description = fmt.Sprintf("Set from the %s variable, which differs by environment: %s.", name, strings.Join(provenance, ", "))
+		if selected != "" {
+			parameters[i].Schema.Default = selected
+			parameters[i].Example = selected
+			continue
+		}
+		b.warnings = append(b.warnings, Warning{
+			Code:    WarnEnvironmentConflict,
+			File:    file,
+			Key:     parameters[i].In + " " + parameters[i].Name,
+			Message: fmt.Sprintf("variable %s differs between environments (%s); select one to document a default", name, strings.Join(provenance, ", ")),
+		})
+	}
+}
+
+// placeholderName returns the variable name when s is exactly one {{name}}
+// placeholder.
+func placeholderName(s string) (string, bool) {
+	m := placeholderRegex.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil || m[0] != strings.TrimSpace(s) {
+		return "", false
+	}
+	return m[1], true
+}
+
+func hasPathParam(params []Parameter, name string) bool {
+	for _, p := range params {
+		if p.In == "path" && p.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// safeJSON parses text as JSON, retrying with comments and trailing
+// commas removed, and falls back to the raw text.
+func safeJSON(text string) any {
+	var out any
+	if err := json.Unmarshal([]byte(text), &out); err == nil {
+		return out
+	}
+	if err := json.Unmarshal([]byte(relaxJSON(text)), &out); err == nil {
+		return out
+	}
+	return text
+}
+
+func splitURL(raw string) (string, string) {
+	if strings.TrimSpace(raw) == "" {
+		return "/", ""
+	}
+	trimmed := strings.TrimSpace(raw)
+	if strings.HasPrefix(trimmed, "{{") && strings.Contains(trimmed, "}}") {
+		endIdx := strings.Index(trimmed, "}}")
+		base := trimmed[:endIdx+2]
+		rest := trimmed[endIdx+2:]
+		if rest == "" {
+			rest = "/"
+		}
+		return rest, base
+	}
+
+	lower := strings.ToLower(trimmed)
+	if strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://") {
+		if u, err := url.Parse(trimmed); err == nil {
+			pathName := u.Path
+			if pathName == "" {
+				pathName = "/"
+			}
+			return pathName, normalizeServerURL(u)
+		}
+		// https://{{host}}/v1 is not a valid URL until resolved; keep the
+		// templated authority as the server.
+		if scheme, rest, ok := strings.Cut(trimmed, "://"); ok && strings.Contains(rest, "{{") {
+			authority, pathName, _ := strings.Cut(rest, "/")
+			return "/" + pathName, strings.ToLower(scheme) + "://" + authority
+		}
+		return "/", ""
+	}
+
+	if strings.HasPrefix(trimmed, "/") {
+		return trimmed, ""
+	}
+	return "/" + trimmed, ""
+}
+
+// resolveURL splits a request URL into path and server, falling back to
+// opts.BaseURL. A server path goes on the server or in front of the
+// request path, as opts.BasePathMode says.
+func resolveURL(raw string, opts Options) (string, string) {
+	pathName, server := splitURL(raw)
+	if server == "" {
+		server = opts.baseURL()
+	}
+	pathName = opts.Variables.Path(pathName)
+	server = opts.Variables.Server(server)
+
+	lower := strings.ToLower(server)
+	if !strings.HasPrefix(lower, "http://") && !strings.HasPrefix(lower, "https://") {
+		return pathName, server
+	}
+	u, err := url.Parse(server)
+	if err != nil {
+		return pathName, server
+	}
+	host := normalizeServerURL(u)
+	basePath := strings.TrimRight(u.Path, "/")
+	if !strings.HasPrefix(pathName, "/") {
+		pathName = "/" + pathName
+	}
+	if basePath == "" {
+		return pathName, host
+	}
+	if opts.BasePathMode == BasePathInPath {
+		if pathName == "/" {
+			return basePath, host
+		}
+		return basePath + pathName, host
+	}
+	return pathName, host + basePath
+}
+
+// normalizeServerURL renders the scheme and host of u in the canonical
+// form used to deduplicate servers: lowercase, without the scheme's
+// default port.
+func normalizeServerURL(u *url.URL) string {
+	scheme := strings.ToLower(u.Scheme)
+	host := strings.ToLower(u.Hostname())
+	port := u.Port()
+	if (scheme == "https" && port == "443") || (scheme == "http" && port == "80") {
+		port = ""
+	}
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	if port != "" {
+		host += ":" + port
+	}
+	return scheme + "://" + host
+}
+
+// normalizeSlashes collapses repeated slashes ({{baseUrl}}//users) and,
+// unless mode is TrailingSlashKeep, strips a trailing slash so /users/ and
+// /users document the same path.
+func normalizeSlashes(pathName, mode string) string {
+	for strings.Contains(pathName, "//") {
+		pathName = strings.ReplaceAll(pathName, "//", "/")
+	}
+	if mode != TrailingSlashKeep && len(pathName) > 1 {
+		pathName = strings.TrimSuffix(pathName, "/")
+	}
+	return pathName
+}
+
+// templatePathVariables turns the {{name}} placeholders left in a path,
+// such as /api/{{version}}/users or a secret kept out of the spec, into
+// {name} path template parameters.
+func templatePathVariables(pathName string) string {
+	return placeholderRegex.ReplaceAllStringFunc(pathName, func(match string) string {
+		name := placeholderRegex.FindStringSubmatch(match)[1]
+		return "{" + nonParamCharRegex.ReplaceAllString(name, "_") + "}"
+	})
+}
+
+var nonParamCharRegex = regexp.MustCompile(`[^A-Za-z0-9_]+`)
+
+// normalizePathParams turns Bruno's :name segments into {name} template
+// parameters. As in Bruno, the parameter is the whole segment, so names
+// may contain hyphens and dots (:order-id, :file.ext), while a colon
+// inside a segment (/users:batch) is left alone.
+func normalizePathParams(pathName string) string {
+	segments := strings.Split(pathName, "/")
+	for i, segment := range segments {
+		if len(segment) > 1 && segment[0] == ':' {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func extractPathParams(pathName string) []string {
+	matches := pathParamRegex.FindAllStringSubmatch(pathName, -1)
+	out := []string{}
+	for _, m := range matches {
+		if len(m) > 1 {
+			out = append(out, m[1])
+		}
+	}
+	return out
+}
+
+// splitContentType returns the content map key for a Content-Type value
+// and its lowercase base media type. Parameters such as charset=utf-8
+// are dropped from the key unless keepParams is set.
+func splitContentType(value string, keepParams bool) (key, base string) {
+	base, _, _ = strings.Cut(value, ";")
+	base = strings.ToLower(strings.TrimSpace(base))
+	if keepParams {
+		return strings.TrimSpace(value), base
+	}
+	return base, base
+}
+
+// WarnUnsupportedMethod is reported for requests whose method OpenAPI
+// does not define and that Options.ExtraMethods does not list.
+const WarnUnsupportedMethod = "unsupported-method"
+
+// openAPIMethods are the methods a path item can hold.
+var openAPIMethods = map[string]bool{
+	"get": true, "put": true, "post": true, "delete": true,
+	"options": true, "head": true, "patch": true, "trace": true,
+}
+
+// bodyAllowed reports whether method has defined request body semantics;
+// GET, HEAD and DELETE bodies are ignored by many servers and proxies.
+func bodyAllowed(method string) bool {
+	switch method {
+	case "get", "head", "delete":
+		return false
+	}
+	return true
+}
+
+// bodyRequired decides requestBody.required. In BodyRequiredAuto mode a
+// body is required on POST, PUT and PATCH unless it is an empty JSON
+// object or array, which suggests the payload is optional.
+func bodyRequired(req Request, mode string) bool {
+	switch mode {
+	case BodyRequiredAlways:
+		return true
+	case BodyRequiredNever:
+		return false
+	}
+	switch req.Method {
+	case "post", "put", "patch":
+	default:
+		return false
+	}
+	switch strings.Join(strings.Fields(req.Body), "") {
+	case "{}", "[]":
+		return false
+	}
+	return true
+}
+
+// bodyContentTypes maps raw body block types to their media type.
+var bodyContentTypes = map[string]string{
+	"json":    "application/json",
+	"text":    "text/plain",
+	"graphql": "application/graphql",
+	"xml":     "application/xml",
+	"sparql":  "application/sparql-query",
+}
+
+func buildRequestBody(req Request, opts Options) *RequestBody {
+	if strings.TrimSpace(req.Body) == "" {
+		return nil
+	}
+	if req.BodyType == "graphql" && opts.GraphQLContentType != GraphQLAsRaw {
+		return buildGraphQLBody(req)
+	}
+	if req.BodyType == "form-urlencoded" {
+		return buildFormBody(req)
+	}
+	if req.BodyType == "multipart-form" {
+		return buildMultipartBody(req)
+	}
+	if req.BodyType == "file" {
+		return buildFileBody(req)
+	}
+
+	contentType, ok := bodyContentTypes[req.BodyType]
+	if !ok {
+		contentType = "application/json"
+	}
+	// Build has canonicalized header names by now.
+	if v, ok := req.Headers["Content-Type"]; ok {
+		contentType = v
+	}
+	contentType, base := splitContentType(contentType, opts.KeepContentTypeParams)
+
+	var media MediaType
+	if strings.Contains(base, "json") {
+		parsed := safeJSON(req.Body)
+		schema := &MediaSchema{Type: "object"}
+		// safeJSON hands back the raw text when the body does not parse.
+		if text, ok := parsed.(string); !ok || text != req.Body {
+			schema = inferJSONSchema(parsed)
+		}
+		media = MediaType{
+			Schema:  schema,
+			Example: parsed,
+		}
+	} else if strings.Contains(base, "xml") {
+		media = MediaType{
+			Schema:  inferXMLSchema(req.Body),
+			Example: req.Body,
+		}
+	} else {
+		media = MediaType{
+			Schema:  &MediaSchema{Type: "string"},
+			Example: req.Body,
+		}
+	}
+
+	return &RequestBody{
+		Required: true,
+		Content: map[string]MediaType{
+			contentType: media,
+		},
+	}
+}
+
+// graphqlOperationRegex finds the name of a named GraphQL operation.
+var graphqlOperationRegex = regexp.MustCompile(`^\s*(?:query|mutation|subscription)\s+([_A-Za-z][_0-9A-Za-z]*)`)
+
+// buildGraphQLBody documents a GraphQL request the way it goes over the
+// wire: a JSON object carrying the query text and its variables, plus the
+// operationName clients send for named operations.
+func buildGraphQLBody(req Request) *RequestBody {
+	variables := map[string]any{}
+	if vars, ok := safeJSON(req.GraphQLVars).(map[string]any); ok {
+		variables = vars
+	}
+	schema := &MediaSchema{
+		Type: "object",
+		Properties: map[string]*MediaSchema{
+			"query":     {Type: "string"},
+			"variables": {Type: "object"},
+		},
+	}
+	example := map[string]any{
+		"query":     req.Body,
+		"variables": variables,
+	}
+	if m := graphqlOperationRegex.FindStringSubmatch(req.Body); m != nil {
+		schema.Properties["operationName"] = &MediaSchema{Type: "string"}
+		example["operationName"] = m[1]
+	}
+	return &RequestBody{
+		Required: true,
+		Content: map[string]MediaType{
+			"application/json": {Schema: schema, Example: example},
+		},
+	}
+}
@@ -0,0 +1,868 @@
+package bruno2openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplitURLNormalizesServer(t *testing.T) {
+	tests := []struct {
+		raw, path, server string
+	}{
+		{"https://API.Example.com/v1", "/v1", "https://api.example.com"},
+		{"https://api.example.com:443/v1", "/v1", "https://api.example.com"},
+		{"HTTP://api.example.com:80/v1", "/v1", "http://api.example.com"},
+		{"https://api.example.com:8443/v1", "/v1", "https://api.example.com:8443"},
+		{"http://api.example.com:443/v1", "/v1", "http://api.example.com:443"},
+		{"http://[::1]:80/", "/", "http://[::1]"},
+		{"HTTPS://{{host}}/v1/users", "/v1/users", "https://{{host}}"},
+	}
+	for _, tt := range tests {
+		path, server := splitURL(tt.raw)
+		if path != tt.path || server != tt.server {
+			t.Errorf("splitURL(%q) = %q, %q; want %q, %q", tt.raw, path, server, tt.path, tt.server)
+		}
+	}
+}
+
+func TestNormalizePathParams(t *testing.T) {
+	tests := []struct {
+		path, want string
+		params     []string
+	}{
+		{"/orders/:orderId/items/:item_id", "/orders/{orderId}/items/{item_id}", []string{"orderId", "item_id"}},
+		{"/orders/:order-id", "/orders/{order-id}", []string{"order-id"}},
+		{"/files/:file.ext", "/files/{file.ext}", []string{"file.ext"}},
+		{"/v1/users:batch", "/v1/users:batch", []string{}},
+		{"/a/:", "/a/:", []string{}},
+	}
+	for _, tt := range tests {
+		got := normalizePathParams(tt.path)
+		if got != tt.want {
+			t.Errorf("normalizePathParams(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+		if params := extractPathParams(got); !reflect.DeepEqual(params, tt.params) {
+			t.Errorf("extractPathParams(%q) = %v, want %v", got, params, tt.params)
+		}
+	}
+}
+
+func TestRelativeURLsUseBaseURL(t *testing.T) {
+	requests := []Request{
+		{Method: "get", URL: "/users"},
+		{Method: "post", URL: "/users"},
+		{Method: "get", URL: "https://other.example.com/health"},
+	}
+	doc, err := Build(requests, Options{BaseURL: "https://api.example.com/v1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	servers := []Server{{URL: "https://api.example.com/v1"}}
+	if !reflect.DeepEqual(doc.Servers, servers) || doc.Paths["/users"] == nil {
+		t.Errorf("got servers %v, paths %v", doc.Servers, sortedKeys(doc.Paths))
+	}
+
+	vars := NewVariables()
+	vars.Set("baseUrl", "https://env.example.com", false)
+	doc, err = Build(requests[:1], Options{Variables: vars})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Servers) != 1 || doc.Servers[0].URL != "https://env.example.com" {
+		t.Errorf("baseUrl variable not used for relative URLs: %v", doc.Servers)
+	}
+}
+
+func TestContentTypeParameters(t *testing.T) {
+	req := Request{
+		Method:   "post",
+		Body:     `{"name": "Ada"}`,
+		BodyType: "text",
+		Headers:  map[string]string{"Content-Type": "Application/JSON; charset=utf-8"},
+	}
+	rb := buildRequestBody(req, Options{})
+	media, ok := rb.Content["application/json"]
+	if !ok {
+		t.Fatalf("want an application/json key, got %v", rb.Content)
+	}
+	if _, ok := media.Example.(map[string]any); !ok {
+		t.Errorf("JSON body not parsed: %#v", media.Example)
+	}
+	rb = buildRequestBody(req, Options{KeepContentTypeParams: true})
+	if _, ok := rb.Content["Application/JSON; charset=utf-8"]; !ok {
+		t.Errorf("parameters not kept: %v", rb.Content)
+	}
+}
+
+func TestExtraMethods(t *testing.T) {
+	requests := []Request{
+		{Method: "get", URL: "/cache", File: "get.bru"},
+		{Method: "trace", URL: "/cache", File: "trace.bru"},
+		{Method: "purge", URL: "/cache", File: "purge.bru"},
+		{Method: "link", URL: "/cache", File: "link.bru"},
+	}
+	doc, err := Build(requests, Options{ExtraMethods: []string{"PURGE"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	item := doc.Paths["/cache"]
+	if len(item.Operations) != 2 || item.Extensions["x-purge"] == nil || item.Extensions["x-link"] != nil {
+		t.Errorf("got operations %v, extensions %v", sortedKeys(item.Operations), sortedKeys(item.Extensions))
+	}
+	if len(doc.Warnings) != 1 || doc.Warnings[0].Code != WarnUnsupportedMethod || doc.Warnings[0].File != "link.bru" {
+		t.Errorf("want an unsupported-method warning for link.bru, got %v", doc.Warnings)
+	}
+}
+
+func TestOperationSummaryFallback(t *testing.T) {
+	requests := []Request{
+		{Method: "get", URL: "/users/:id", Name: "Unnamed", Description: "Fetches a user."},
+		{Method: "delete", URL: "/users/:id", Name: "  "},
+		{Method: "post", URL: "/users", Name: "Create User"},
+	}
+	doc, err := Build(requests, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"get": "GET /users/{id}", "delete": "DELETE /users/{id}"}
+	for method, summary := range want {
+		if got := doc.Paths["/users/{id}"].Operations[method].Summary; got != summary {
+			t.Errorf("%s summary = %q, want %q", method, got, summary)
+		}
+	}
+	if op := doc.Paths["/users/{id}"].Operations["get"]; op.Description != "Fetches a user." {
+		t.Errorf("docs not used as description: %q", op.Description)
+	}
+	if got := doc.Paths["/users"].Operations["post"].Summary; got != "Create User" {
+		t.Errorf("summary = %q, want the meta name", got)
+	}
+}
+
+func TestRequestBodyRequired(t *testing.T) {
+	tests := []struct {
+		method, body, mode string
+		want               bool
+	}{
+		{"post", `{"name": "Ada"}`, "", true},
+		{"patch", `{ }`, BodyRequiredAuto, false},
+		{"delete", `{"reason": "spam"}`, "", false},
+		{"delete", `{"reason": "spam"}`, BodyRequiredAlways, true},
+		{"put", `{"name": "Ada"}`, BodyRequiredNever, false},
+	}
+	for _, tt := range tests {
+		req := Request{Method: tt.method, Body: tt.body, BodyType: "json"}
+		if got := bodyRequired(req, tt.mode); got != tt.want {
+			t.Errorf("%s %s (%q): required = %v, want %v", tt.method, tt.body, tt.mode, got, tt.want)
+		}
+	}
+
+	requests := []Request{{Method: "get", URL: "/search", File: "search.bru", Body: `{"q": "x"}`, BodyType: "json"}}
+	doc, err := Build(requests, Options{SkipUnexpectedBodies: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.Paths["/search"].Operations["get"].RequestBody != nil {
+		t.Error("GET body not skipped")
+	}
+	if len(doc.Warnings) != 1 || doc.Warnings[0].Code != WarnBodyNotAllowed {
+		t.Errorf("want a body-not-allowed warning, got %v", doc.Warnings)
+	}
+}
+
+func TestNormalizeSlashes(t *testing.T) {
+	tests := []struct {
+		path, mode, want string
+	}{
+		{"/users/", "", "/users"},
+		{"//users//42/", TrailingSlashStrip, "/users/42"},
+		{"/users/", TrailingSlashKeep, "/users/"},
+		{"//", "", "/"},
+		{"/", TrailingSlashKeep, "/"},
+	}
+	for _, tt := range tests {
+		if got := normalizeSlashes(tt.path, tt.mode); got != tt.want {
+			t.Errorf("normalizeSlashes(%q, %q) = %q, want %q", tt.path, tt.mode, got, tt.want)
+		}
+	}
+
+	doc, err := Build([]Request{
+		{Method: "get", URL: "{{baseUrl}}/users/"},
+		{Method: "post", URL: "{{baseUrl}}//users"},
+	}, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Paths) != 1 || len(doc.Paths["/users"].Operations) != 2 {
+		t.Errorf("paths not deduplicated: %v", sortedKeys(doc.Paths))
+	}
+}
+
+func TestTemplatePathVariables(t *testing.T) {
+	vars := NewVariables()
+	vars.Set("tenant", "acme", false)
+	vars.Set("apiKey", "s3cret", true)
+	requests := []Request{
+		{Method: "get", URL: "{{baseUrl}}/api/{{version}}/users/:id"},
+		{Method: "get", URL: "{{baseUrl}}/{{tenant}}/keys/{{apiKey}}"},
+		{Method: "get", URL: "{{baseUrl}}/env/{{process.env.STAGE}}"},
+	}
+	doc, err := Build(requests, Options{Variables: vars})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{"/api/{version}/users/{id}", "/acme/keys/{apiKey}", "/env/{process_env_STAGE}"} {
+		item, ok := doc.Paths[path]
+		if !ok {
+			t.Errorf("missing path %s in %v", path, sortedKeys(doc.Paths))
+			continue
+		}
+		for _, name := range extractPathParams(path) {
+			if !hasPathParam(item.Operations["get"].Parameters, name) {
+				t.Errorf("%s: no path parameter %s", path, name)
+			}
+		}
+	}
+	if warnings := Validate(doc); len(warnings) != 0 {
+		t.Errorf("unexpected validation findings: %v", warnings)
+	}
+}
+
+func TestBuildDeduplicatesServersByNormalizedHost(t *testing.T) {
+	requests := []Request{
+		{Method: "get", URL: "https://API.Example.com/v1/users"},
+		{Method: "get", URL: "https://api.example.com:443/v1/orders"},
+		{Method: "get", URL: "https://api.example.com:8443/v1/admin"},
+	}
+	doc := buildOpenAPI(requests, Options{})
+	want := []Server{{URL: "https://api.example.com"}}
+	if !reflect.DeepEqual(doc.Servers, want) {
+		t.Errorf("servers = %v, want %v", doc.Servers, want)
+	}
+	if servers := doc.Paths["/v1/users"].Operations["get"].Servers; servers != nil {
+		t.Errorf("operation on the main host lists servers: %v", servers)
+	}
+	admin := []Server{{URL: "https://api.example.com:8443"}}
+	if servers := doc.Paths["/v1/admin"].Operations["get"].Servers; !reflect.DeepEqual(servers, admin) {
+		t.Errorf("operation servers = %v, want %v", servers, admin)
+	}
+}
+
+func TestGraphQLContentTypeRaw(t *testing.T) {
+	req := Request{Method: "post", URL: "/graphql", BodyType: "graphql", Body: "{ viewer { id } }", GraphQLVars: `{"a": 1}`}
+	rb := buildRequestBody(req, Options{GraphQLContentType: "application/graphql"})
+	media, ok := rb.Content["application/graphql"]
+	if !ok || media.Example != req.Body {
+		t.Errorf("expected raw application/graphql body, got %+v", rb.Content)
+	}
+
+	rb = buildRequestBody(req, Options{})
+	example, _ := rb.Content["application/json"].Example.(map[string]any)
+	if example["query"] != req.Body || !reflect.DeepEqual(example["variables"], map[string]any{"a": float64(1)}) {
+		t.Errorf("unexpected JSON GraphQL example: %v", example)
+	}
+	if _, named := example["operationName"]; named {
+		t.Errorf("anonymous query got an operationName: %v", example)
+	}
+
+	req.Body = "mutation RenameUser($id: ID!) {\n  renameUser(id: $id) { id }\n}"
+	rb = buildRequestBody(req, Options{})
+	example, _ = rb.Content["application/json"].Example.(map[string]any)
+	if example["operationName"] != "RenameUser" {
+		t.Errorf("operationName not derived from the named mutation: %v", example)
+	}
+}
+
+func TestHeaderParametersIgnore(t *testing.T) {
+	req := Request{Headers: map[string]string{
+		"X-Tenant-Id":         "acme",
+		"X-Gateway-Key":       "k",
+		"Content-Type":        "application/json",
+		"Authorization":       "Bearer t",
+		"Connection":          "keep-alive",
+		"Host":                "api.example.com",
+		"Proxy-Authorization": "Basic x",
+	}}
+	params := headerParameters(req, Options{HeaderIgnore: []string{"x-gateway-key"}})
+	if len(params) != 1 || params[0].Name != "X-Tenant-Id" || params[0].In != "header" {
+		t.Errorf("unexpected header parameters: %+v", params)
+	}
+}
+
+func TestInferJSONSchema(t *testing.T) {
+	req := Request{Method: "post", URL: "/orders", File: "orders.bru", BodyType: "json", Body: `{
+		"id": 42,
+		"total": 9.5,
+		"paid": false,
+		"note": null,
+		"placedAt": "2024-05-01T10:00:00Z",
+		"customer": {"name": "Ana", "zip": "007"},
+		"items": [{"sku": "A1", "qty": 2}],
+		"flags": []
+	}`}
+	schema := buildOpenAPI([]Request{req}, Options{}).Paths["/orders"].Operations["post"].RequestBody.Content["application/json"].Schema
+	props := schema.Properties
+	checks := map[string]string{
+		"id":       props["id"].Type,
+		"total":    props["total"].Type,
+		"paid":     props["paid"].Type,
+		"placedAt": props["placedAt"].Type + "/" + props["placedAt"].Format,
+		"zip":      props["customer"].Properties["zip"].Type,
+		"qty":      props["items"].Items.Properties["qty"].Type,
+	}
+	want := map[string]string{
+		"id":       "integer",
+		"total":    "number",
+		"paid":     "boolean",
+		"placedAt": "string/date-time",
+		"zip":      "string",
+		"qty":      "integer",
+	}
+	if !reflect.DeepEqual(checks, want) {
+		t.Errorf("got %v, want %v", checks, want)
+	}
+	if !props["note"].Nullable || props["note"].Type != "" {
+		t.Errorf("null not nullable: %+v", props["note"])
+	}
+	if props["flags"].Type != "array" || props["flags"].Items == nil {
+		t.Errorf("empty array without items: %+v", props["flags"])
+	}
+	if props["id"].Example != int64(42) {
+		t.Errorf("integer example = %#v", props["id"].Example)
+	}
+
+	req.Body = `{"broken": `
+	schema = buildOpenAPI([]Request{req}, Options{}).Paths["/orders"].Operations["post"].RequestBody.Content["application/json"].Schema
+	if schema.Type != "object" || schema.Properties != nil {
+		t.Errorf("unparseable body should stay a bare object: %+v", schema)
+	}
+}
+
+func TestInferJSONSchemaArrays(t *testing.T) {
+	var body any
+	if err := json.Unmarshal([]byte(`{
+		"lines": [
+			{"sku": "A1", "qty": 2},
+			{"sku": "B2", "qty": 1.5, "note": "gift"},
+			{"sku": null, "qty": 3, "tags": [["x"], ["y", "z"]]}
+		],
+		"mixed": [1, "two", 3, {"four": 4}],
+		"empty": []
+	}`), &body); err != nil {
+		t.Fatal(err)
+	}
+	props := inferJSONSchema(body).Properties
+
+	lines := props["lines"].Items
+	if lines.Type != "object" || len(lines.Properties) != 4 {
+		t.Fatalf("array of objects not merged: %+v", lines)
+	}
+	if lines.Properties["qty"].Type != "number" {
+		t.Errorf("integer and number not widened: %+v", lines.Properties["qty"])
+	}
+	if sku := lines.Properties["sku"]; sku.Type != "string" || !sku.Nullable {
+		t.Errorf("null element not merged as nullable: %+v", sku)
+	}
+	if tags := lines.Properties["tags"]; tags.Items.Type != "array" || tags.Items.Items.Type != "string" {
+		t.Errorf("nested arrays not inferred: %+v", tags)
+	}
+
+	mixed := props["mixed"].Items
+	types := []string{}
+	for _, variant := range mixed.OneOf {
+		types = append(types, variant.Type)
+	}
+	if mixed.Type != "" || strings.Join(types, ",") != "integer,string,object" {
+		t.Errorf("mixed array: type %q, oneOf %v", mixed.Type, types)
+	}
+	if empty := props["empty"].Items; empty.Type != "" || empty.OneOf != nil {
+		t.Errorf("empty array items = %+v", empty)
+	}
+}
+
+func TestRequiredProperties(t *testing.T) {
+	requests := []Request{
+		{Method: "post", URL: "/orders", Name: "Create Order", File: "create.bru", BodyType: "json", Body: `{"sku": "A1", "qty": 1, "lines": [{"n": 1, "gift": true}, {"n": 2}]}`},
+		{Method: "post", URL: "/orders", Name: "Create Gift Order", File: "gift.bru", BodyType: "json", Body: `{"sku": "B2", "note": null}`},
+	}
+	tests := []struct {
+		mode         string
+		order, lines []string
+	}{
+		{RequiredPropertiesNone, nil, nil},
+		{RequiredPropertiesAll, []string{"lines", "note", "qty", "sku"}, []string{"gift", "n"}},
+		{RequiredPropertiesIntersection, []string{"sku"}, []string{"n"}},
+	}
+	for _, tt := range tests {
+		doc, err := Build(requests, Options{RequiredProperties: tt.mode})
+		if err != nil {
+			t.Fatal(err)
+		}
+		schema := doc.Paths["/orders"].Operations["post"].RequestBody.Content["application/json"].Schema
+		if !reflect.DeepEqual(schema.Required, tt.order) || !reflect.DeepEqual(schema.Properties["lines"].Items.Required, tt.lines) {
+			t.Errorf("%s: got %v and %v, want %v and %v", tt.mode, schema.Required, schema.Properties["lines"].Items.Required, tt.order, tt.lines)
+		}
+	}
+	if _, err := Build(requests, Options{RequiredProperties: "some"}); err == nil {
+		t.Error("unknown required properties mode accepted")
+	}
+}
+
+func TestCookieParameters(t *testing.T) {
+	req := Request{Method: "get", URL: "/me", File: "me.bru", Headers: map[string]string{
+		"Cookie":     `session={{sessionId}}; theme="dark"; ;flag`,
+		"X-Trace-Id": "1",
+	}}
+	doc := buildOpenAPI([]Request{req}, Options{})
+	got := []string{}
+	for _, p := range doc.Paths["/me"].Operations["get"].Parameters {
+		got = append(got, fmt.Sprintf("%s:%s=%v", p.In, p.Name, p.Example))
+	}
+	want := "header:X-Trace-Id=1,cookie:session={{sessionId}},cookie:theme=dark,cookie:flag="
+	if strings.Join(got, ",") != want {
+		t.Errorf("got %v, want %s", got, want)
+	}
+
+	params := headerParameters(req, Options{KeepCookieHeader: true})
+	if len(params) != 2 || params[0].Name != "Cookie" {
+		t.Errorf("cookie header not kept: %+v", params)
+	}
+	if cookies := cookieParameters(req, Options{KeepCookieHeader: true}); len(cookies) != 0 {
+		t.Errorf("cookies split despite KeepCookieHeader: %+v", cookies)
+	}
+}
+
+func TestUnresolvedVariables(t *testing.T) {
+	requests := []Request{{
+		Method:     "post",
+		URL:        "/login",
+		File:       "login.bru",
+		Headers:    map[string]string{"X-Request-Id": "{{$guid}}", "X-Tenant": "{{tenant}}"},
+		Body:       `{"user": "{{user}}", "password": "{{password}}"}`,
+		BodyType:   "json",
+		FolderVars: []FolderVar{{Name: "tenant", Value: "acme", Folder: "."}},
+	}}
+	vars := NewVariables()
+	vars.Set("user", "ada", false)
+
+	doc, err := Build(requests, Options{Variables: vars, Unresolved: UnresolvedBlank})
+	if err != nil {
+		t.Fatal(err)
+	}
+	op := doc.Paths["/login"].Operations["post"]
+	example := op.RequestBody.Content["application/json"].Example.(map[string]any)
+	if example["user"] != "ada" || example["password"] != "" {
+		t.Errorf("unexpected example: %v", example)
+	}
+	for _, p := range op.Parameters {
+		if p.Example == "" {
+			t.Errorf("%s blanked, but folder and dynamic variables are not unresolved", p.Name)
+		}
+	}
+
+	_, err = Build(requests, Options{Variables: vars, Unresolved: UnresolvedError})
+	if err == nil || !strings.Contains(err.Error(), "{{password}} in login.bru") {
+		t.Errorf("want an error naming {{password}}, got %v", err)
+	}
+	if _, err := Build(requests, Options{Unresolved: "drop"}); err == nil {
+		t.Error("unknown unresolved mode accepted")
+	}
+}
+
+func TestInferQueryTypes(t *testing.T) {
+	tests := []struct {
+		example     string
+		typ, format string
+		want        any
+	}{
+		{"42", "integer", "", int64(42)},
+		{"-3.5", "number", "", -3.5},
+		{"true", "boolean", "", true},
+		{"2024-01-01", "string", "date", "2024-01-01"},
+		{"2024-01-01T10:00:00Z", "string", "date-time", "2024-01-01T10:00:00Z"},
+		{"007", "string", "", "007"},
+		{"{{page}}", "string", "", "{{page}}"},
+		{"3F2504E0-4F89-11D3-9A0C-0305E82C3301", "string", "uuid", "3F2504E0-4F89-11D3-9A0C-0305E82C3301"},
+		{"ana@example.com", "string", "email", "ana@example.com"},
+		{"https://example.com/cb?x=1", "string", "uri", "https://example.com/cb?x=1"},
+		{"/callback", "string", "", "/callback"},
+		{"a@b", "string", "", "a@b"},
+	}
+	for _, tt := range tests {
+		params := []Parameter{{Name: "q", In: "query", Schema: Schema{Type: "string"}, Example: tt.example}}
+		inferQueryTypes(params)
+		if params[0].Schema.Type != tt.typ || params[0].Schema.Format != tt.format || params[0].Example != tt.want {
+			t.Errorf("%q: got %s/%s %#v, want %s/%s %#v", tt.example, params[0].Schema.Type, params[0].Schema.Format, params[0].Example, tt.typ, tt.format, tt.want)
+		}
+	}
+
+	params := []Parameter{{Name: "region", In: "query", Schema: Schema{Type: "string", Default: "eu"}, Example: "1"}}
+	inferQueryTypes(params)
+	if params[0].Schema.Type != "string" {
+		t.Errorf("example and default disagree, want string, got %s", params[0].Schema.Type)
+	}
+	doc, err := Build([]Request{{Method: "get", URL: "/items", Query: map[string]string{"limit": "20"}}}, Options{NoTypeInference: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := doc.Paths["/items"].Operations["get"].Parameters[0]; p.Schema.Type != "string" || p.Example != "20" {
+		t.Errorf("NoTypeInference ignored: %+v", p)
+	}
+}
+
+func TestSafeJSONLenient(t *testing.T) {
+	body := `{
+  "name": "Ada", // the user's name
+  /* "role": "admin", */
+  "url": "https://example.com/a,]",
+  "tags": ["a", "b",],
+}`
+	got := safeJSON(body)
+	want := map[string]any{"name": "Ada", "url": "https://example.com/a,]", "tags": []any{"a", "b"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %#v, want %#v", got, want)
+	}
+	if got := safeJSON("{{payload}}"); got != "{{payload}}" {
+		t.Errorf("non-JSON body should stay raw, got %#v", got)
+	}
+}
+
+func TestOnConflict(t *testing.T) {
+	requests := []Request{
+		{Method: "get", URL: "/users", Name: "List Users", File: "list.bru", Query: map[string]string{"page": "1"}},
+		{Method: "get", URL: "/users", Name: "Search Users", File: "search.bru", Query: map[string]string{"q": "ada"}},
+	}
+	params := func(doc OpenAPI, path string) []string {
+		names := []string{}
+		for _, p := range doc.Paths[path].Operations["get"].Parameters {
+			names = append(names, p.Name)
+		}
+		return names
+	}
+
+	doc, err := Build(requests, Options{OnConflict: OnConflictKeepFirst})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := params(doc, "/users"); !reflect.DeepEqual(got, []string{"page"}) {
+		t.Errorf("keep-first: got parameters %v", got)
+	}
+	if len(doc.Warnings) != 1 || doc.Warnings[0].Code != WarnDuplicateOperation || doc.Warnings[0].File != "search.bru" {
+		t.Errorf("keep-first: want a duplicate-operation warning for search.bru, got %v", doc.Warnings)
+	}
+
+	doc, err = Build(requests, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := params(doc, "/users"); !reflect.DeepEqual(got, []string{"page", "q"}) {
+		t.Errorf("merge: got parameters %v", got)
+	}
+
+	doc, err = Build(requests, Options{OnConflict: OnConflictSuffix})
+	if err != nil {
+		t.Fatal(err)
+	}
+	item := doc.Paths["/users"]
+	dup, ok := item.Extensions["x-get-2"].(Operation)
+	if len(doc.Paths) != 1 || !ok {
+		t.Fatalf("suffix: got paths %v, extensions %v", sortedKeys(doc.Paths), item.Extensions)
+	}
+	if dup.OperationID == item.Operations["get"].OperationID || dup.Extensions["x-bruno-duplicate-of"] != item.Operations["get"].OperationID {
+		t.Errorf("suffix: got operationId %q, x-bruno-duplicate-of %v", dup.OperationID, dup.Extensions["x-bruno-duplicate-of"])
+	}
+	if len(dup.Parameters) != 1 || dup.Parameters[0].Name != "q" {
+		t.Errorf("suffix: got parameters %v", dup.Parameters)
+	}
+
+	if _, err := Build(requests, Options{OnConflict: OnConflictError}); err == nil || !strings.Contains(err.Error(), "GET /users (search.bru)") {
+		t.Errorf("error: got %v", err)
+	}
+}
+
+func TestMergeRequestExamples(t *testing.T) {
+	requests := []Request{
+		{Method: "post", URL: "/orders", Name: "Create Order", File: "create.bru", BodyType: "json", Body: `{"sku": "A1"}`},
+		{Method: "post", URL: "/orders", Name: "Create Gift Order", File: "gift.bru", BodyType: "json", Body: `{"sku": "A1", "gift": true, "note": null}`},
+		{Method: "post", URL: "/orders", Name: "Create Noted Order", File: "noted.bru", BodyType: "json", Body: `{"sku": null, "note": "ring twice"}`},
+	}
+	doc, err := Build(requests, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	media := doc.Paths["/orders"].Operations["post"].RequestBody.Content["application/json"]
+	want := map[string]Example{
+		"Create Order":       {Value: map[string]any{"sku": "A1"}},
+		"Create Gift Order":  {Value: map[string]any{"sku": "A1", "gift": true, "note": nil}},
+		"Create Noted Order": {Value: map[string]any{"sku": nil, "note": "ring twice"}},
+	}
+	if media.Example != nil || !reflect.DeepEqual(media.Examples, want) {
+		t.Errorf("got example %v, examples %v", media.Example, media.Examples)
+	}
+	props := media.Schema.Properties
+	for _, name := range []string{"sku", "note"} {
+		if props[name].Type != "string" || !props[name].Nullable {
+			t.Errorf("%s: want nullable string, got %+v", name, props[name])
+		}
+	}
+	if props["gift"].Type != "boolean" || props["gift"].Nullable {
+		t.Errorf("gift: want boolean, got %+v", props["gift"])
+	}
+}
+
+func TestCanonicalHeaders(t *testing.T) {
+	headers, warnings := canonicalHeaders(map[string]string{
+		"content-type":  "application/xml",
+		"~Content-Type": "text/plain",
+		"x-trace":       "a",
+		"X-TRACE":       "b",
+		"~x-debug":      "true",
+	}, "orders.bru")
+	want := map[string]string{"Content-Type": "application/xml", "X-Trace": "b", "~X-Debug": "true"}
+	if !reflect.DeepEqual(headers, want) {
+		t.Errorf("got %v, want %v", headers, want)
+	}
+	if len(warnings) != 1 || warnings[0].Code != WarnDuplicateHeader || warnings[0].Key != "X-Trace" {
+		t.Errorf("want one duplicate-header warning for X-Trace, got %v", warnings)
+	}
+}
+
+func TestIncludeDisabled(t *testing.T) {
+	req := Request{
+		Headers: map[string]string{"X-Tenant-Id": "acme", "~X-Debug": "true"},
+		Query:   map[string]string{"page": "1", "~page": "2", "~verbose": "true"},
+	}
+	if params := headerParameters(req, Options{}); len(params) != 1 {
+		t.Errorf("disabled header documented: %+v", params)
+	}
+	params := headerParameters(req, Options{IncludeDisabled: true})
+	if len(params) != 2 || params[0].Name != "X-Debug" || params[0].Description == "" {
+		t.Errorf("disabled header not included: %+v", params)
+	}
+
+	entries := keyValueEntries(req.Query, true)
+	want := []keyValueEntry{{name: "page", value: "1"}, {name: "verbose", value: "true", disabled: true}}
+	if !reflect.DeepEqual(entries, want) {
+		t.Errorf("got %+v, want %+v", entries, want)
+	}
+}
+
+func TestResolveURLBasePath(t *testing.T) {
+	tests := []struct {
+		baseURL, mode, path, server string
+	}{
+		{"https://api.example.com", BasePathInServer, "/users", "https://api.example.com"},
+		{"https://api.example.com/", BasePathInServer, "/users", "https://api.example.com"},
+		{"https://api.example.com/api/v1", BasePathInServer, "/users", "https://api.example.com/api/v1"},
+		{"https://api.example.com/api/v1/", BasePathInServer, "/users", "https://api.example.com/api/v1"},
+		{"https://api.example.com", BasePathInPath, "/users", "https://api.example.com"},
+		{"https://api.example.com/", BasePathInPath, "/users", "https://api.example.com"},
+		{"https://api.example.com/api/v1", BasePathInPath, "/api/v1/users", "https://api.example.com"},
+		{"https://API.example.com:443/api/v1/", BasePathInPath, "/api/v1/users", "https://api.example.com"},
+	}
+	for _, tt := range tests {
+		vars := NewVariables()
+		vars.Set("baseUrl", tt.baseURL, false)
+		opts := Options{Variables: vars, BasePathMode: tt.mode}
+		path, server := resolveURL("{{baseUrl}}/users", opts)
+		if path != tt.path || server != tt.server {
+			t.Errorf("baseUrl %q (%s): got %q, %q; want %q, %q", tt.baseURL, tt.mode, path, server, tt.path, tt.server)
+		}
+	}
+
+	vars := NewVariables()
+	vars.Set("baseUrl", "https://api.example.com/api/v1/", false)
+	if path, _ := resolveURL("{{baseUrl}}", Options{Variables: vars, BasePathMode: BasePathInPath}); path != "/api/v1" {
+		t.Errorf("bare base URL in path mode: got %q", path)
+	}
+	if path, server := resolveURL("{{baseUrl}}", Options{Variables: vars}); path != "/" || server != "https://api.example.com/api/v1" {
+		t.Errorf("bare base URL in server mode: got %q, %q", path, server)
+	}
+}
+
+func TestDescriptionTemplate(t *testing.T) {
+	requests := []Request{
+		{Method: "get", URL: "/users", Name: "List Users", File: "users/list.bru"},
+		{Method: "post", URL: "/users", Name: "Create User", Description: "Creates a user."},
+	}
+	doc, err := Build(requests, Options{DescriptionTemplate: "Performs {method} on {path}. Source: {file}."})
+	if err != nil {
+		t.Fatal(err)
+	}
+	list := doc.Paths["/users"].Operations["get"]
+	if list.Description != "Performs GET on /users. Source: users/list.bru." || list.Extensions["x-generated-description"] != true {
+		t.Errorf("unexpected generated description: %q %v", list.Description, list.Extensions)
+	}
+	create := doc.Paths["/users"].Operations["post"]
+	if create.Description != "Creates a user." || create.Extensions != nil {
+		t.Errorf("authored description replaced: %q %v", create.Description, create.Extensions)
+	}
+
+	if _, err := Build(requests, Options{DescriptionTemplate: "{verb} {path}"}); err == nil || !strings.Contains(err.Error(), "{verb}") {
+		t.Errorf("unknown token accepted: %v", err)
+	}
+}
+
+func TestOrderByPathIsStable(t *testing.T) {
+	requests := []Request{
+		{Method: "delete", URL: "/users/:id", File: "users/delete.bru"},
+		{Method: "post", URL: "/users", File: "users/create.bru"},
+		{Method: "put", URL: "/users/:id", File: "users/replace.bru"},
+		{Method: "post", URL: "/users/:id", File: "users/copy.bru"},
+		{Method: "get", URL: "/users/:id", File: "users/get.bru"},
+		{Method: "patch", URL: "/users/:id", File: "users/update.bru"},
+		{Method: "get", URL: "/health", File: "health.bru"},
+	}
+	var first []byte
+	for run := 0; run < 5; run++ {
+		doc, err := Build(requests, Options{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		out, err := MarshalYAML(doc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if first == nil {
+			first = out
+		} else if string(out) != string(first) {
+			t.Fatalf("output differs between runs:\n%s\n---\n%s", first, out)
+		}
+	}
+	last := -1
+	for _, want := range []string{"/health:", "/users:", "/users/{id}:", "get:", "post:", "put:", "patch:", "delete:"} {
+		i := strings.Index(string(first)[last+1:], want)
+		if i < 0 {
+			t.Fatalf("%q missing or out of order in:\n%s", want, first)
+		}
+		last += i + 1
+	}
+}
+
+func TestOrderBySeq(t *testing.T) {
+	requests := []Request{
+		{Method: "get", URL: "/users", Name: "List Users", Tag: "users", File: "users/list.bru", Seq: 2},
+		{Method: "post", URL: "/users", Name: "Create User", Tag: "users", File: "users/create.bru", Seq: 1},
+		{Method: "post", URL: "/auth/login", Name: "Login", Tag: "auth", File: "auth/login.bru", Seq: 1},
+		{Method: "get", URL: "/users/:id", Name: "Get User", Tag: "users", File: "users/get.bru"},
+		{Method: "get", URL: "/health", Name: "Health", File: "health.bru", Seq: 1},
+	}
+	doc, err := Build(requests, Options{Ordering: OrderBySeq})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := MarshalYAML(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	last := -1
+	for _, want := range []string{"/health:", "/auth/login:", "/users:", "post:", "get:", "/users/{id}:"} {
+		i := strings.Index(string(out)[last+1:], want)
+		if i < 0 {
+			t.Fatalf("%q missing or out of order in:\n%s", want, out)
+		}
+		last += i + 1
+	}
+	if doc.Tags[0].Name != "auth" || doc.Tags[1].Name != "users" {
+		t.Errorf("tags not in collection order: %v", doc.Tags)
+	}
+
+	if _, err := Build(requests, Options{Ordering: "random"}); err == nil {
+		t.Error("unknown ordering accepted")
+	}
+}
+
+func TestTagStrategyGroups(t *testing.T) {
+	requests := []Request{
+		{Method: "get", URL: "/admin/users/roles", Name: "List Roles", Tag: "admin/users/roles"},
+		{Method: "get", URL: "/admin/users", Name: "List Users", Tag: "admin/users"},
+		{Method: "get", URL: "/public/users", Name: "Public Users", Tag: "public/users"},
+		{Method: "get", URL: "/health", Name: "Health", Tags: []string{"ops"}},
+	}
+	doc := buildOpenAPI(requests, Options{TagStrategy: TagStrategyGroups})
+
+	if tags := doc.Paths["/admin/users/roles"].Operations["get"].Tags; !reflect.DeepEqual(tags, []string{"roles"}) {
+		t.Errorf("leaf folder tag = %v", tags)
+	}
+	if tags := doc.Paths["/public/users"].Operations["get"].Tags; !reflect.DeepEqual(tags, []string{"public/users"}) {
+		t.Errorf("colliding leaf should keep its path, got %v", tags)
+	}
+	want := []TagGroup{
+		{Name: "admin", Tags: []string{"roles", "users"}},
+		{Name: "public", Tags: []string{"public/users"}},
+		{Name: OtherTagGroup, Tags: []string{"ops"}},
+	}
+	if got := doc.Extensions["x-tagGroups"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("x-tagGroups = %v, want %v", got, want)
+	}
+
+	if doc := buildOpenAPI(requests, Options{}); doc.Extensions != nil {
+		t.Errorf("path strategy emitted %v", doc.Extensions)
+	}
+}
+
+func TestInfoContactAndLicense(t *testing.T) {
+	requests := []Request{{Method: "get", URL: "/health", Name: "Health"}}
+	doc := buildOpenAPI(requests, Options{
+		TermsOfService: "https://example.com/terms",
+		Contact:        &Contact{Email: "api@example.com"},
+		License:        &License{Name: "Apache-2.0", URL: "https://www.apache.org/licenses/LICENSE-2.0"},
+	})
+	if doc.Info.TermsOfService != "https://example.com/terms" || doc.Info.Contact.Email != "api@example.com" || doc.Info.License.Name != "Apache-2.0" {
+		t.Errorf("unexpected info %+v", doc.Info)
+	}
+
+	doc = buildOpenAPI(requests, Options{Contact: &Contact{}, License: &License{URL: "https://example.com/license"}})
+	if doc.Info.Contact != nil || doc.Info.License != nil {
+		t.Errorf("empty contact or nameless license emitted: %+v", doc.Info)
+	}
+}
+
+func TestFileBodyContentType(t *testing.T) {
+	body := "file: @file(photo.png) @contentType(image/png)"
+	requests := []Request{
+		{Method: "put", URL: "/photos/:id", File: "photo.bru", BodyType: "file", Body: body},
+		{Method: "put", URL: "/avatars/:id", File: "avatar.bru", BodyType: "file", Body: body,
+			Headers: map[string]string{"Content-Type": "image/webp"}},
+	}
+	doc, err := Build(requests, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for path, want := range map[string]string{"/photos/{id}": "image/png", "/avatars/{id}": "image/webp"} {
+		content := doc.Paths[path].Operations["put"].RequestBody.Content
+		if _, ok := content[want]; !ok || len(content) != 1 {
+			t.Errorf("%s: want %s, got %v", path, want, sortedKeys(content))
+		}
+	}
+}
+
+func TestFolderDefaultsQueryAndPathOnly(t *testing.T) {
+	requests := []Request{{
+		Method:     "get",
+		URL:        "/users",
+		File:       "users.bru",
+		Query:      map[string]string{"~page": "{{page}}", "limit": "{{limit}}"},
+		Headers:    map[string]string{"X-Region": "{{region}}"},
+		FolderVars: []FolderVar{{Name: "page", Value: "1", Folder: "."}, {Name: "limit", Value: "20", Folder: "users"}, {Name: "region", Value: "eu", Folder: "."}},
+	}}
+	doc, err := Build(requests, Options{IncludeDisabled: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]Parameter{}
+	for _, p := range doc.Paths["/users"].Operations["get"].Parameters {
+		got[p.Name] = p
+	}
+	if p := got["page"]; fmt.Sprint(p.Schema.Default) != "1" || p.Description != "Disabled in the Bruno request." {
+		t.Errorf("disabled query parameter: default %v, description %q", p.Schema.Default, p.Description)
+	}
+	if p := got["limit"]; fmt.Sprint(p.Schema.Default) != "20" || p.Description != "Defaults to the limit variable of folder users." {
+		t.Errorf("query parameter: default %v, description %q", p.Schema.Default, p.Description)
+	}
+	if p := got["X-Region"]; p.Schema.Default != nil || p.Description != "" || p.Example != "{{region}}" {
+		t.Errorf("header took a folder default: %+v", p)
+	}
+}
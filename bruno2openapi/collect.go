@@ -0,0 +1,278 @@
+package bruno2openapi
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+const (
+	folderFile     = "folder.bru"
+	collectionFile = "collection.bru"
+)
+
+// isFolderFile reports whether rel, relative to the collection root, holds
+// folder defaults rather than a request: any folder.bru, and the root
+// collection.bru whose defaults apply to the whole collection.
+func isFolderFile(rel string) bool {
+	return path.Base(rel) == folderFile || rel == collectionFile
+}
+
+// ProgressFunc is called after each file is parsed with the number of
+// files done so far and the total.
+type ProgressFunc func(done, total int)
+
+// CollectRequests parses every .bru file below root in fsys. Each request
+// is tagged with its folder path relative to root. A file with malformed
+// syntax comes back as a Skipped request whose WarnSyntax warning names
+// the file and line. folder.bru files describe their folder and the root
+// collection.bru the whole collection, rather than a request; their vars,
+// headers and auth apply to the requests below them, and each comes back
+// as a FolderFile entry carrying its own warnings. Folders on the
+// bruno.json ignore list are skipped.
+func CollectRequests(fsys fs.FS, root string) ([]Request, error) {
+	return CollectRequestsProgress(fsys, root, nil)
+}
+
+// CollectRequestsProgress is CollectRequests with progress reporting; a
+// nil progress is allowed.
+func CollectRequestsProgress(fsys fs.FS, root string, progress ProgressFunc) ([]Request, error) {
+	cfg, err := ReadConfig(fsys, root)
+	if err != nil {
+		return nil, err
+	}
+	files, err := collectBruFiles(fsys, root, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("reading Bruno directory: %w", err)
+	}
+
+	done := 0
+	step := func() {
+		done++
+		if progress != nil {
+			progress(done, len(files))
+		}
+	}
+
+	folders := map[string]Request{}
+	requests := []Request{}
+	requestFiles := []string{}
+	for _, file := range files {
+		if !isFolderFile(relPath(root, file)) {
+			requestFiles = append(requestFiles, file)
+			continue
+		}
+		folder, err := parseFile(fsys, root, file)
+		if skipped, ok := skippedFile(err); ok {
+			requests = append(requests, skipped)
+			step()
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		folder.FolderFile = true
+		requests = append(requests, folder)
+		folders[path.Dir(folder.File)] = folder
+		step()
+	}
+
+	for _, file := range requestFiles {
+		parsed, err := parseFile(fsys, root, file)
+		if skipped, ok := skippedFile(err); ok {
+			requests = append(requests, skipped)
+			step()
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		dir := path.Dir(parsed.File)
+		if dir != "." {
+			parsed.Tag = dir
+		}
+		parsed.FolderVars = folderVars(folders, dir)
+		parsed.Folders = folderChain(folders, dir)
+		if parsed.Auth == nil && parsed.AuthMode == "inherit" {
+			parsed.Auth = inheritedAuth(parsed.Folders)
+		}
+		inheritHeaders(&parsed)
+		requests = append(requests, parsed)
+		step()
+	}
+	return requests, nil
+}
+
+func parseFile(fsys fs.FS, root, file string) (Request, error) {
+	if info, err := fs.Stat(fsys, file); err == nil && info.Size() > MaxFileSize {
+		return Request{}, fmt.Errorf("%s is %d bytes: %w of %d bytes", file, info.Size(), ErrFileTooLarge, MaxFileSize)
+	}
+	content, err := fs.ReadFile(fsys, file)
+	if err != nil {
+		return Request{}, fmt.Errorf("reading file %s: %w", file, err)
+	}
+	rel := relPath(root, file)
+	parsed, err := parseBru(decodeText(content))
+	if err != nil {
+		var perr *ParseError
+		if errors.As(err, &perr) {
+			perr.File = rel
+		}
+		return Request{}, err
+	}
+	parsed.File = rel
+	for i := range parsed.Warnings {
+		parsed.Warnings[i].File = rel
+	}
+	return parsed, nil
+}
+
+// skippedFile turns a *ParseError into a Skipped request carrying it as a
+// WarnSyntax warning, so one malformed file does not stop the collection.
+func skippedFile(err error) (Request, bool) {
+	var perr *ParseError
+	if !errors.As(err, &perr) {
+		return Request{}, false
+	}
+	return Request{
+		File:    perr.File,
+		Skipped: true,
+		Warnings: []Warning{{
+			Code:    WarnSyntax,
+			File:    perr.File,
+			Line:    perr.Line,
+			Message: perr.Msg + "; file skipped",
+		}},
+	}, true
+}
+
+// folderVars gathers the vars of dir and its ancestors, nearest folder
+// first. A variable redefined closer to the request shadows the outer one.
+func folderVars(folders map[string]Request, dir string) []FolderVar {
+	vars := []FolderVar{}
+	seen := map[string]bool{}
+	for {
+		if folder, ok := folders[dir]; ok {
+			for _, name := range sortedKeys(folder.Vars) {
+				if !seen[name] {
+					seen[name] = true
+					vars = append(vars, FolderVar{Name: name, Value: folder.Vars[name], Folder: dir})
+				}
+			}
+		}
+		if dir == "." {
+			return vars
+		}
+		dir = path.Dir(dir)
+	}
+}
+
+// collectionFolder returns what collection.bru gives the collection, as
+// carried in the folder chain of the requests.
+func collectionFolder(requests []Request) (Folder, bool) {
+	for _, req := range requests {
+		for _, f := range req.Folders {
+			if f.Path == "." {
+				return f, true
+			}
+		}
+	}
+	return Folder{}, false
+}
+
+// folderChain returns the folder.bru metadata of dir and its ancestors,
+// nearest folder first.
+func folderChain(folders map[string]Request, dir string) []Folder {
+	chain := []Folder{}
+	for {
+		if folder, ok := folders[dir]; ok {
+			f := Folder{
+				Path:         dir,
+				Description:  folder.Description,
+				Headers:      folder.Headers,
+				Auth:         folder.Auth,
+				AuthMode:     folder.AuthMode,
+				Deprecated:   folder.IsDeprecated(),
+				ExternalDocs: folder.ExternalDocs,
+			}
+			if folder.Name != "Unnamed" {
+				f.Name = folder.Name
+			}
+			chain = append(chain, f)
+		}
+		if dir == "." {
+			return chain
+		}
+		dir = path.Dir(dir)
+	}
+}
+
+// inheritedAuth returns the auth of the nearest folder that defines one;
+// a folder that itself inherits defers to its parent, one set to none
+// stops the search.
+func inheritedAuth(chain []Folder) *Auth {
+	for _, f := range chain {
+		if f.AuthMode == "none" {
+			return nil
+		}
+		if f.Auth != nil {
+			return f.Auth
+		}
+	}
+	return nil
+}
+
+// inheritHeaders adds the headers of the request's folders, nearest first,
+// unless the request (or a nearer folder) already sets them. Names compare
+// case-insensitively and regardless of the disabled (~) prefix.
+func inheritHeaders(req *Request) {
+	have := map[string]bool{}
+	for name := range req.Headers {
+		have[strings.ToLower(strings.TrimPrefix(name, "~"))] = true
+	}
+	for _, f := range req.Folders {
+		for _, name := range sortedKeys(f.Headers) {
+			key := strings.ToLower(strings.TrimPrefix(name, "~"))
+			if !have[key] {
+				have[key] = true
+				req.Headers[name] = f.Headers[name]
+			}
+		}
+	}
+}
+
+func collectBruFiles(fsys fs.FS, root string, cfg Config) ([]string, error) {
+	results := []string{}
+	walkFn := func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			rel := relPath(root, path)
+			// Environment files are not requests; see LoadEnvironments.
+			if path != root && cfg.SkipsDir(rel) {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if strings.HasSuffix(d.Name(), ".bru") {
+			results = append(results, path)
+		}
+		return nil
+	}
+	if err := fs.WalkDir(fsys, root, walkFn); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// relPath returns file relative to root, both being slash-separated fs.FS
+// paths.
+func relPath(root, file string) string {
+	if root == "." || root == "" {
+		return file
+	}
+	return strings.TrimPrefix(file, strings.TrimSuffix(root, "/")+"/")
+}
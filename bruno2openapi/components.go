@@ -0,0 +1,124 @@
+package bruno2openapi
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// schemaUse is one request body media type whose schema may be shared.
+type schemaUse struct {
+	op    Operation
+	media string
+}
+
+// shareSchemas moves request body schemas that several operations share
+// into components/schemas and replaces them with a $ref. Schemas are
+// compared structurally, ignoring examples. A shared schema is named after
+// the folder tag of its operations when they all have the same one, and
+// after the first operation otherwise, e.g. UsersBody or CreateUserBody.
+func shareSchemas(paths map[string]*PathItem, components *Components) *Components {
+	groups := map[string][]schemaUse{}
+	order := []string{}
+	for _, pathName := range sortedKeys(paths) {
+		item := paths[pathName]
+		for _, method := range item.methods() {
+			op := item.Operations[method]
+			if op.RequestBody == nil {
+				continue
+			}
+			for _, media := range sortedKeys(op.RequestBody.Content) {
+				schema := op.RequestBody.Content[media].Schema
+				if schema == nil || schema.Type != "object" || len(schema.Properties) == 0 {
+					continue
+				}
+				key := schemaKey(schema)
+				if _, ok := groups[key]; !ok {
+					order = append(order, key)
+				}
+				groups[key] = append(groups[key], schemaUse{op: op, media: media})
+			}
+		}
+	}
+
+	for _, key := range order {
+		uses := groups[key]
+		if len(uses) < 2 {
+			continue
+		}
+		if components == nil {
+			components = &Components{}
+		}
+		if components.Schemas == nil {
+			components.Schemas = map[string]*MediaSchema{}
+		}
+		base := sharedSchemaName(uses)
+		name := base
+		for n := 2; components.Schemas[name] != nil; n++ {
+			name = base + strconv.Itoa(n)
+		}
+		components.Schemas[name] = withoutExamples(uses[0].op.RequestBody.Content[uses[0].media].Schema)
+		for _, use := range uses {
+			// Operations are values, but Content is a map shared with the
+			// operation stored in the path item.
+			media := use.op.RequestBody.Content[use.media]
+			media.Schema = &MediaSchema{Ref: "#/components/schemas/" + name}
+			use.op.RequestBody.Content[use.media] = media
+		}
+	}
+	return components
+}
+
+// schemaKey identifies a schema by its structure.
+func schemaKey(s *MediaSchema) string {
+	key, _ := json.Marshal(withoutExamples(s))
+	return string(key)
+}
+
+// withoutExamples returns a deep copy of s with every example removed.
+func withoutExamples(s *MediaSchema) *MediaSchema {
+	if s == nil {
+		return nil
+	}
+	cp := *s
+	cp.Example = nil
+	cp.Items = withoutExamples(s.Items)
+	if s.OneOf != nil {
+		cp.OneOf = make([]*MediaSchema, len(s.OneOf))
+		for i, variant := range s.OneOf {
+			cp.OneOf[i] = withoutExamples(variant)
+		}
+	}
+	if s.Properties != nil {
+		cp.Properties = make(map[string]*MediaSchema, len(s.Properties))
+		for name, prop := range s.Properties {
+			cp.Properties[name] = withoutExamples(prop)
+		}
+	}
+	return &cp
+}
+
+func sharedSchemaName(uses []schemaUse) string {
+	source := ""
+	if len(uses[0].op.Tags) > 0 {
+		source = uses[0].op.Tags[0]
+		for _, use := range uses[1:] {
+			if len(use.op.Tags) == 0 || use.op.Tags[0] != source {
+				source = ""
+				break
+			}
+		}
+	}
+	if source == "" {
+		source = uses[0].op.OperationID
+	}
+	var sb strings.Builder
+	for _, w := range identifierWords(source) {
+		r := []rune(w)
+		r[0] = unicode.ToUpper(r[0])
+		sb.WriteString(string(r))
+	}
+	sb.WriteString("Body")
+	return sb.String()
+}
@@ -0,0 +1,97 @@
+package bruno2openapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+)
+
+const configFile = "bruno.json"
+
+// SupportedFormatVersion is the bruno.json collection format version this
+// converter understands.
+const SupportedFormatVersion = "1"
+
+// WarnFormatVersion is reported for collections in a format version other
+// than SupportedFormatVersion.
+const WarnFormatVersion = "format-version"
+
+// defaultIgnore is what Bruno itself skips when bruno.json has no ignore
+// list.
+var defaultIgnore = []string{"node_modules", ".git"}
+
+// Config is the collection's bruno.json.
+type Config struct {
+	Version string   `json:"version"`
+	Name    string   `json:"name"`
+	Type    string   `json:"type"`
+	Ignore  []string `json:"ignore"`
+	// OpenAPI holds converter settings kept in bruno.json; Bruno ignores
+	// the key.
+	OpenAPI OpenAPIConfig `json:"openapi"`
+}
+
+// OpenAPIConfig is the "openapi" object of bruno.json, which fills the info
+// block unless command line flags override it.
+type OpenAPIConfig struct {
+	Title          string   `json:"title"`
+	Version        string   `json:"version"`
+	Description    string   `json:"description"`
+	TermsOfService string   `json:"termsOfService"`
+	Contact        *Contact `json:"contact"`
+	License        *License `json:"license"`
+}
+
+// ReadConfig reads bruno.json from root in fsys. A collection without one
+// gets the zero Config and no error.
+func ReadConfig(fsys fs.FS, root string) (Config, error) {
+	var cfg Config
+	content, err := fs.ReadFile(fsys, path.Join(root, configFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, fmt.Errorf("reading %s: %w", configFile, err)
+	}
+	if err := json.Unmarshal([]byte(decodeText(content)), &cfg); err != nil {
+		return cfg, fmt.Errorf("parsing %s: %w", configFile, err)
+	}
+	return cfg, nil
+}
+
+// Warnings reports configuration the converter may not handle fully.
+func (c Config) Warnings() []Warning {
+	if c.Version == "" || c.Version == SupportedFormatVersion {
+		return nil
+	}
+	return []Warning{{
+		Code:    WarnFormatVersion,
+		File:    configFile,
+		Key:     "version",
+		Message: fmt.Sprintf("collection format version %s is not version %s; newer syntax may be converted incompletely", c.Version, SupportedFormatVersion),
+	}}
+}
+
+// SkipsDir reports whether CollectRequests leaves out the directory rel
+// (relative to the collection root): environments, which holds no
+// requests, and directories on the ignore list.
+func (c Config) SkipsDir(rel string) bool {
+	return rel == environmentsDir || c.ignored(rel)
+}
+
+// ignored reports whether the directory rel (relative to the collection
+// root) is skipped: its name or its full path is on the ignore list.
+func (c Config) ignored(rel string) bool {
+	ignore := c.Ignore
+	if ignore == nil {
+		ignore = defaultIgnore
+	}
+	for _, entry := range ignore {
+		if entry == rel || entry == path.Base(rel) {
+			return true
+		}
+	}
+	return false
+}
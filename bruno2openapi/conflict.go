@@ -0,0 +1,168 @@
+package bruno2openapi
+
+import (
+	"fmt"
+	"strings"
+)
+
+// WarnDuplicateOperation is reported when two requests map to the same
+// path and method.
+const WarnDuplicateOperation = "duplicate-operation"
+
+// resolveConflict decides where op, converted from req, goes when its
+// path and method are already taken, according to opts.OnConflict. It
+// returns the path to store op under and the operation to store, or ok
+// false when op is dropped or, for OnConflictSuffix, already stored as a
+// path item extension.
+func (b *builder) resolveConflict(pathName string, req Request, op Operation) (string, Operation, bool) {
+	item := b.pathItem(pathName)
+	existing, taken := item.Operations[req.Method]
+	if !taken {
+		return pathName, op, true
+	}
+	endpoint := strings.ToUpper(req.Method) + " " + pathName
+	first := b.sources[pathName][req.Method]
+	warn := func(format string, args ...any) {
+		b.warnings = append(b.warnings, Warning{
+			Code:    WarnDuplicateOperation,
+			File:    req.File,
+			Key:     endpoint,
+			Message: fmt.Sprintf("same endpoint as %s; ", first) + fmt.Sprintf(format, args...),
+		})
+	}
+	switch b.opts.OnConflict {
+	case OnConflictKeepFirst:
+		warn("kept %s", first)
+		return "", op, false
+	case OnConflictSuffix:
+		if item.Extensions == nil {
+			item.Extensions = map[string]any{}
+		}
+		for n := 2; ; n++ {
+			key := fmt.Sprintf("x-%s-%d", req.Method, n)
+			if _, taken := item.Extensions[key]; !taken {
+				op.setExtension("x-bruno-duplicate-of", existing.OperationID)
+				item.Extensions[key] = op
+				b.sources[pathName][strings.TrimPrefix(key, "x-")] = req.File
+				warn("documented as %s on %s", key, pathName)
+				return "", op, false
+			}
+		}
+	case OnConflictError:
+		warn("conflicting operations")
+		return "", op, false
+	default:
+		warn("merged into one operation")
+		return pathName, mergeOperations(existing, op), true
+	}
+}
+
+// mergeOperations folds other into op: parameters op lacks are added (as
+// optional, since not every request sends them), request body examples
+// become named examples, one per request, and responses are added or,
+// for status codes both document, merged the same way.
+func mergeOperations(op, other Operation) Operation {
+	have := map[string]bool{}
+	for _, p := range op.Parameters {
+		have[p.In+":"+strings.ToLower(p.Name)] = true
+	}
+	for _, p := range other.Parameters {
+		if have[p.In+":"+strings.ToLower(p.Name)] {
+			continue
+		}
+		if p.In != "path" {
+			p.Required = false
+		}
+		op.Parameters = append(op.Parameters, p)
+	}
+	op.RequestBody = mergeRequestBodies(op, other)
+	for code, resp := range other.Responses {
+		existing, ok := op.Responses[code]
+		if !ok {
+			op.Responses[code] = resp
+			continue
+		}
+		for name, header := range resp.Headers {
+			if existing.Headers == nil {
+				existing.Headers = map[string]Header{}
+			}
+			if _, ok := existing.Headers[name]; !ok {
+				existing.Headers[name] = header
+			}
+		}
+		existing.Content = mergeContent(existing.Content, resp.Content, op, other)
+		op.Responses[code] = existing
+	}
+	return op
+}
+
+// mergeRequestBodies combines the request bodies of op and other.
+func mergeRequestBodies(op, other Operation) *RequestBody {
+	if op.RequestBody == nil || other.RequestBody == nil {
+		if op.RequestBody == nil {
+			return other.RequestBody
+		}
+		return op.RequestBody
+	}
+	return &RequestBody{
+		Required: op.RequestBody.Required && other.RequestBody.Required,
+		Content:  mergeContent(op.RequestBody.Content, other.RequestBody.Content, op, other),
+	}
+}
+
+// exampleName keys the example a request contributes to a merged
+// operation: the request name, which the operation's summary holds.
+func exampleName(op Operation) string {
+	return firstNonEmpty(op.Summary, op.OperationID)
+}
+
+// addExample adds example under name, or under fallback when another
+// request already used name.
+func addExample(examples map[string]Example, name, fallback string, example Example) {
+	if _, dup := examples[name]; dup {
+		name = fallback
+	}
+	examples[name] = example
+}
+
+// mergeContent combines the content of a request body or response of op
+// and other. Media types both document get the merged schema, so a
+// property null in one example and set in the other becomes nullable, and
+// list each request's example under its name.
+func mergeContent(content, more map[string]MediaType, op, other Operation) map[string]MediaType {
+	if content == nil {
+		return more
+	}
+	merged := map[string]MediaType{}
+	for contentType, media := range content {
+		merged[contentType] = media
+	}
+	for contentType, media := range more {
+		existing, ok := merged[contentType]
+		if !ok {
+			merged[contentType] = media
+			continue
+		}
+		if existing.Schema != nil && media.Schema != nil {
+			existing.Schema = mergeSchemas([]*MediaSchema{existing.Schema, media.Schema})
+		}
+		if existing.Examples == nil {
+			existing.Examples = map[string]Example{}
+			if existing.Example != nil {
+				addExample(existing.Examples, exampleName(op), op.OperationID, Example{Value: existing.Example})
+			}
+			existing.Example = nil
+		}
+		if media.Example != nil {
+			addExample(existing.Examples, exampleName(other), other.OperationID, Example{Value: media.Example})
+		}
+		for _, name := range sortedKeys(media.Examples) {
+			addExample(existing.Examples, name, other.OperationID+" "+name, media.Examples[name])
+		}
+		if len(existing.Examples) == 0 {
+			existing.Examples = nil
+		}
+		merged[contentType] = existing
+	}
+	return merged
+}
@@ -0,0 +1,158 @@
+package bruno2openapi
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite testdata/corpus golden files")
+
+const corpusDir = "testdata/corpus"
+
+// TestCorpus converts every testdata/corpus/<name>/collection and compares
+// the result with testdata/corpus/<name>/openapi.yml. Run with -update to
+// regenerate the golden files after an intended output change.
+func TestCorpus(t *testing.T) {
+	entries, err := os.ReadDir(corpusDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		name := entry.Name()
+		t.Run(name, func(t *testing.T) {
+			dir := filepath.Join(corpusDir, name)
+			got := convertCollection(t, filepath.Join(dir, "collection"))
+			compareGolden(t, filepath.Join(dir, "openapi.yml"), got)
+		})
+	}
+}
+
+func convertCollection(t testing.TB, inputDir string) []byte {
+	t.Helper()
+	requests, err := CollectRequests(os.DirFS(inputDir), ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err := Build(requests, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := MarshalYAML(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func compareGolden(t *testing.T, goldenPath string, got []byte) {
+	t.Helper()
+	if *update {
+		if err := os.WriteFile(goldenPath, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(goldenPath)
+	if err != nil {
+		t.Fatalf("missing golden file (run go test -run TestCorpus -update): %v", err)
+	}
+	if string(want) != string(got) {
+		t.Errorf("output differs from %s (-want +got):\n%s", goldenPath, lineDiff(string(want), string(got)))
+	}
+}
+
+// lineDiff renders a minimal line-based diff of want and got, keeping two
+// lines of context around each change.
+func lineDiff(want, got string) string {
+	a := strings.Split(want, "\n")
+	b := strings.Split(got, "\n")
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	type line struct {
+		op   byte
+		text string
+	}
+	lines := []line{}
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			lines = append(lines, line{' ', a[i]})
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			lines = append(lines, line{'-', a[i]})
+			i++
+		default:
+			lines = append(lines, line{'+', b[j]})
+			j++
+		}
+	}
+
+	const context = 2
+	var sb strings.Builder
+	lastPrinted := -1
+	for idx, l := range lines {
+		if l.op == ' ' {
+			continue
+		}
+		start := max(idx-context, lastPrinted+1)
+		if lastPrinted >= 0 && start > lastPrinted+1 {
+			sb.WriteString("...\n")
+		}
+		for k := start; k <= idx; k++ {
+			fmt.Fprintf(&sb, "%c %s\n", lines[k].op, lines[k].text)
+		}
+		lastPrinted = idx
+		for k := idx + 1; k < len(lines) && k <= idx+context && lines[k].op == ' '; k++ {
+			fmt.Fprintf(&sb, "%c %s\n", lines[k].op, lines[k].text)
+			lastPrinted = k
+		}
+	}
+	return sb.String()
+}
+
+// TestCorpusDeterministic converts every corpus collection repeatedly and
+// expects byte-identical output, since map iteration order is random.
+func TestCorpusDeterministic(t *testing.T) {
+	entries, err := os.ReadDir(corpusDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		name := entry.Name()
+		t.Run(name, func(t *testing.T) {
+			dir := filepath.Join(corpusDir, name, "collection")
+			first := convertCollection(t, dir)
+			for i := 0; i < 20; i++ {
+				if got := convertCollection(t, dir); string(got) != string(first) {
+					t.Fatalf("run %d differs:\n%s", i+2, lineDiff(string(first), string(got)))
+				}
+			}
+		})
+	}
+}
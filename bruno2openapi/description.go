@@ -0,0 +1,50 @@
+package bruno2openapi
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var templateTokenRegex = regexp.MustCompile(`\{(\w+)\}`)
+
+// descriptionTokens lists the tokens accepted by
+// Options.DescriptionTemplate and how each is filled in.
+var descriptionTokens = map[string]func(req Request, pathName, operationID string) string{
+	"method":      func(req Request, _, _ string) string { return strings.ToUpper(req.Method) },
+	"path":        func(_ Request, pathName, _ string) string { return pathName },
+	"file":        func(req Request, _, _ string) string { return req.File },
+	"name":        func(req Request, _, _ string) string { return req.Name },
+	"tag":         func(req Request, _, _ string) string { return req.Tag },
+	"operationId": func(_ Request, _, operationID string) string { return operationID },
+}
+
+// DescriptionTokens returns the tokens a description template may use,
+// sorted.
+func DescriptionTokens() []string {
+	return sortedKeys(descriptionTokens)
+}
+
+// checkDescriptionTemplate rejects templates using unknown tokens.
+func checkDescriptionTemplate(template string) error {
+	unknown := []string{}
+	for _, m := range templateTokenRegex.FindAllStringSubmatch(template, -1) {
+		if _, ok := descriptionTokens[m[1]]; !ok {
+			unknown = append(unknown, "{"+m[1]+"}")
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("unknown description template token(s) %s (available: {%s})",
+			strings.Join(unknown, ", "), strings.Join(DescriptionTokens(), "}, {"))
+	}
+	return nil
+}
+
+// renderDescription fills in the tokens of a validated template.
+func renderDescription(template string, req Request, pathName, operationID string) string {
+	return templateTokenRegex.ReplaceAllStringFunc(template, func(token string) string {
+		return descriptionTokens[strings.Trim(token, "{}")](req, pathName, operationID)
+	})
+}
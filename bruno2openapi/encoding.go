@@ -0,0 +1,69 @@
+package bruno2openapi
+
+import (
+	"bytes"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+var (
+	bomUTF8    = []byte{0xEF, 0xBB, 0xBF}
+	bomUTF16LE = []byte{0xFF, 0xFE}
+	bomUTF16BE = []byte{0xFE, 0xFF}
+)
+
+// decodeText returns content as UTF-8 text. Files saved by Windows editors
+// may start with a byte order mark or be UTF-16; a BOM is dropped and
+// UTF-16 transcoded. Content that is not valid UTF-8 is read as
+// Windows-1252, the usual culprit on Windows.
+func decodeText(content []byte) string {
+	switch {
+	case bytes.HasPrefix(content, bomUTF8):
+		content = content[len(bomUTF8):]
+	case bytes.HasPrefix(content, bomUTF16LE):
+		return decodeUTF16(content[2:], false)
+	case bytes.HasPrefix(content, bomUTF16BE):
+		return decodeUTF16(content[2:], true)
+	}
+	if utf8.Valid(content) {
+		return string(content)
+	}
+	return decodeWindows1252(content)
+}
+
+func decodeUTF16(content []byte, bigEndian bool) string {
+	units := make([]uint16, 0, len(content)/2)
+	for i := 0; i+1 < len(content); i += 2 {
+		if bigEndian {
+			units = append(units, uint16(content[i])<<8|uint16(content[i+1]))
+		} else {
+			units = append(units, uint16(content[i+1])<<8|uint16(content[i]))
+		}
+	}
+	return string(utf16.Decode(units))
+}
+
+// windows1252 maps the bytes 0x80-0x9F, where Windows-1252 differs from
+// Latin-1; zero entries are undefined and decode as U+FFFD.
+var windows1252 = [32]rune{
+	'€', 0, '‚', 'ƒ', '„', '…', '†', '‡', 'ˆ', '‰', 'Š', '‹', 'Œ', 0, 'Ž', 0,
+	0, '‘', '’', '“', '”', '•', '–', '—', '˜', '™', 'š', '›', 'œ', 0, 'ž', 'Ÿ',
+}
+
+func decodeWindows1252(content []byte) string {
+	var b strings.Builder
+	for _, c := range content {
+		switch {
+		case c >= 0x80 && c <= 0x9F:
+			r := windows1252[c-0x80]
+			if r == 0 {
+				r = utf8.RuneError
+			}
+			b.WriteRune(r)
+		default:
+			b.WriteRune(rune(c))
+		}
+	}
+	return b.String()
+}
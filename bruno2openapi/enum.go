@@ -0,0 +1,123 @@
+package bruno2openapi
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// WarnUnknownEnum is reported when an @enum annotation names neither a
+// parameter nor a request body property of its operation.
+const WarnUnknownEnum = "unknown-enum"
+
+// enumAnnotationRegex matches @enum(name: a|b|c) in a docs block.
+var enumAnnotationRegex = regexp.MustCompile(`@enum\(\s*([^:()\s]+)\s*:\s*([^()]*)\)`)
+
+// extractEnums removes the @enum(name: a|b) annotations from docs and
+// returns the remaining text with the allowed values by name. Lines that
+// held nothing but annotations are dropped.
+func extractEnums(docs string) (string, map[string][]string) {
+	if !strings.Contains(docs, "@enum(") {
+		return docs, nil
+	}
+	enums := map[string][]string{}
+	lines := []string{}
+	for _, line := range strings.Split(docs, "\n") {
+		matches := enumAnnotationRegex.FindAllStringSubmatch(line, -1)
+		if matches == nil {
+			lines = append(lines, line)
+			continue
+		}
+		for _, m := range matches {
+			values := []string{}
+			for _, v := range strings.Split(m[2], "|") {
+				if v = strings.TrimSpace(v); v != "" {
+					values = append(values, v)
+				}
+			}
+			if len(values) > 0 {
+				enums[m[1]] = values
+			}
+		}
+		if rest := strings.TrimRight(enumAnnotationRegex.ReplaceAllString(line, ""), " \t"); strings.TrimSpace(rest) != "" {
+			lines = append(lines, rest)
+		}
+	}
+	if len(enums) == 0 {
+		enums = nil
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n")), enums
+}
+
+// applyEnums restricts the parameters and request body properties named
+// by the request's @enum annotations to the annotated values. Body
+// properties match by name at any depth and get values of their own type.
+func (b *builder) applyEnums(req Request, pathName string, op *Operation) {
+	for _, name := range sortedKeys(req.Enums) {
+		values := req.Enums[name]
+		found := false
+		for i := range op.Parameters {
+			if op.Parameters[i].Name == name {
+				op.Parameters[i].Schema.Enum = values
+				found = true
+			}
+		}
+		if op.RequestBody != nil {
+			for _, media := range op.RequestBody.Content {
+				if enumProperty(media.Schema, name, values) {
+					found = true
+				}
+			}
+		}
+		if !found {
+			b.warnings = append(b.warnings, Warning{
+				Code:    WarnUnknownEnum,
+				File:    req.File,
+				Key:     operationKey(req.Method, pathName),
+				Message: fmt.Sprintf("@enum(%s) matches no parameter or request body property", name),
+			})
+		}
+	}
+}
+
+// enumProperty sets the enum of every property called name below s and
+// reports whether there was one.
+func enumProperty(s *MediaSchema, name string, values []string) bool {
+	if s == nil {
+		return false
+	}
+	found := false
+	for propName, prop := range s.Properties {
+		if propName == name && prop.Type != "object" && prop.Type != "array" {
+			prop.Enum = typedEnum(prop.Type, values)
+			found = true
+		}
+		if enumProperty(prop, name, values) {
+			found = true
+		}
+	}
+	if enumProperty(s.Items, name, values) {
+		found = true
+	}
+	for _, variant := range s.OneOf {
+		if enumProperty(variant, name, values) {
+			found = true
+		}
+	}
+	return found
+}
+
+// typedEnum converts annotated values to the property's type, keeping
+// values that do not convert as strings.
+func typedEnum(typ string, values []string) []any {
+	out := make([]any, len(values))
+	for i, v := range values {
+		out[i] = v
+		if typ == "integer" || typ == "number" || typ == "boolean" {
+			if t, _, value := inferScalar(v); t == typ || (typ == "number" && t == "integer") {
+				out[i] = value
+			}
+		}
+	}
+	return out
+}
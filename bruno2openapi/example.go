@@ -0,0 +1,197 @@
+package bruno2openapi
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ResponseExample is a saved response from an example block:
+//
+//	example {
+//	  name: Created
+//	  response: {
+//	    status: {
+//	      code: 201
+//	    }
+//	    headers: {
+//	      Content-Type: application/json
+//	    }
+//	    body: {
+//	      type: json
+//	      content: '''
+//	        {"id": 1}
+//	      '''
+//	    }
+//	  }
+//	}
+type ResponseExample struct {
+	Name        string
+	Description string
+	// Status is the response status code, 200 when the example has none.
+	Status   int
+	Headers  map[string]string
+	BodyType string
+	Body     string
+}
+
+// parseExample reads the nested key: { ... } structure of an example
+// block. ok is false when the block has no response.
+func parseExample(lines []string) (ResponseExample, bool) {
+	root := map[string]any{}
+	stack := []map[string]any{root}
+	multiline := ""
+	var text []string
+	for _, raw := range lines {
+		line := strings.TrimSpace(raw)
+		if multiline != "" {
+			if line == "'''" {
+				stack[len(stack)-1][multiline] = strings.TrimSpace(dedent(text))
+				multiline, text = "", nil
+				continue
+			}
+			text = append(text, raw)
+			continue
+		}
+		if line == "" || isComment(line) {
+			continue
+		}
+		if line == "}" {
+			if len(stack) > 1 {
+				stack = stack[:len(stack)-1]
+			}
+			continue
+		}
+		key, value := splitKeyValue(line)
+		if key == "" {
+			continue
+		}
+		current := stack[len(stack)-1]
+		switch value {
+		case "{":
+			child := map[string]any{}
+			current[key] = child
+			stack = append(stack, child)
+		case "'''":
+			multiline = key
+		default:
+			current[key] = value
+		}
+	}
+
+	response, ok := root["response"].(map[string]any)
+	if !ok {
+		return ResponseExample{}, false
+	}
+	ex := ResponseExample{
+		Name:        stringField(root, "name"),
+		Description: stringField(root, "description"),
+		Status:      http.StatusOK,
+	}
+	code := stringField(response, "status")
+	if status, ok := response["status"].(map[string]any); ok {
+		code = stringField(status, "code")
+	}
+	if n, ok := parseStatusCode(code); ok {
+		ex.Status = n
+	}
+	if headers, ok := response["headers"].(map[string]any); ok {
+		ex.Headers = map[string]string{}
+		for name, value := range headers {
+			if s, ok := value.(string); ok {
+				ex.Headers[http.CanonicalHeaderKey(name)] = s
+			}
+		}
+	}
+	if body, ok := response["body"].(map[string]any); ok {
+		ex.BodyType = strings.ToLower(stringField(body, "type"))
+		ex.Body = stringField(body, "content")
+	}
+	return ex, true
+}
+
+func stringField(m map[string]any, key string) string {
+	s, _ := m[key].(string)
+	return s
+}
+
+// exampleContentTypes maps example body types to media types.
+var exampleContentTypes = map[string]string{
+	"json": "application/json",
+	"xml":  "application/xml",
+	"html": "text/html",
+	"text": "text/plain",
+}
+
+// exampleResponses documents the request's saved response examples by
+// status code. Each response gets the examples' headers and, per media
+// type, a schema merged from every example body plus the bodies
+// themselves, each named after its example.
+func exampleResponses(req Request) map[string]Response {
+	if len(req.Examples) == 0 {
+		return nil
+	}
+	responses := map[string]Response{}
+	for i, ex := range req.Examples {
+		status := strconv.Itoa(ex.Status)
+		resp, ok := responses[status]
+		if !ok {
+			resp = Response{Description: firstNonEmpty(ex.Description, http.StatusText(ex.Status), ex.Name)}
+		}
+		for name, value := range ex.Headers {
+			if lower := strings.ToLower(name); lower == "content-type" || hopByHopHeaders[lower] {
+				continue
+			}
+			if resp.Headers == nil {
+				resp.Headers = map[string]Header{}
+			}
+			if _, ok := resp.Headers[name]; !ok {
+				resp.Headers[name] = Header{Schema: &Schema{Type: "string"}, Example: value}
+			}
+		}
+		if strings.TrimSpace(ex.Body) == "" {
+			responses[status] = resp
+			continue
+		}
+
+		contentType := exampleContentTypes[ex.BodyType]
+		if v, ok := ex.Headers["Content-Type"]; ok {
+			contentType, _ = splitContentType(v, false)
+		}
+		if contentType == "" {
+			contentType = "text/plain"
+		}
+		var value any = ex.Body
+		schema := &MediaSchema{Type: "string"}
+		if strings.Contains(contentType, "json") {
+			value = safeJSON(ex.Body)
+			if text, ok := value.(string); !ok || text != ex.Body {
+				schema = inferJSONSchema(value)
+			}
+		} else if strings.Contains(contentType, "xml") {
+			schema = inferXMLSchema(ex.Body)
+		}
+
+		if resp.Content == nil {
+			resp.Content = map[string]MediaType{}
+		}
+		media, exists := resp.Content[contentType]
+		if !exists {
+			media.Schema = schema
+		} else if media.Schema != nil {
+			media.Schema = mergeSchemas([]*MediaSchema{media.Schema, schema})
+		}
+		if media.Examples == nil {
+			media.Examples = map[string]Example{}
+		}
+		name := firstNonEmpty(ex.Name, fmt.Sprintf("example%d", i+1))
+		if _, dup := media.Examples[name]; dup {
+			name = fmt.Sprintf("%s%d", name, i+1)
+		}
+		media.Examples[name] = Example{Summary: ex.Description, Value: value}
+		resp.Content[contentType] = media
+		responses[status] = resp
+	}
+	return responses
+}
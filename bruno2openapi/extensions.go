@@ -0,0 +1,26 @@
+package bruno2openapi
+
+import (
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// WarnUnknownOpenAPIKey is reported for keys of an openapi block that are
+// not x- vendor extensions.
+const WarnUnknownOpenAPIKey = "unknown-openapi-key"
+
+// isExtensionKey reports whether key names a vendor extension (x-...).
+func isExtensionKey(key string) bool {
+	return strings.HasPrefix(strings.ToLower(key), "x-") && len(key) > 2
+}
+
+// extensionValue decodes the raw value of an x- key the way YAML would,
+// so true, 30 and [a, b] keep their type; values YAML rejects stay text.
+func extensionValue(raw string) any {
+	var value any
+	if err := yaml.Unmarshal([]byte(raw), &value); err != nil || value == nil {
+		return raw
+	}
+	return value
+}
@@ -0,0 +1,35 @@
+package bruno2openapi
+
+import (
+	"regexp"
+	"strings"
+)
+
+// externalDocsRegex matches @docs(url) and @docs(url, description) in a
+// docs block.
+var externalDocsRegex = regexp.MustCompile(`@docs\(\s*([^\s,()]+)\s*(?:,\s*([^()]*?))?\s*\)`)
+
+// extractExternalDocs removes the @docs(url, description) annotations from
+// docs and returns the remaining text with the first annotation's link.
+// Lines that held nothing but annotations are dropped.
+func extractExternalDocs(docs string) (string, *ExternalDocs) {
+	if !strings.Contains(docs, "@docs(") {
+		return docs, nil
+	}
+	var link *ExternalDocs
+	lines := []string{}
+	for _, line := range strings.Split(docs, "\n") {
+		matches := externalDocsRegex.FindAllStringSubmatch(line, -1)
+		if matches == nil {
+			lines = append(lines, line)
+			continue
+		}
+		if link == nil {
+			link = &ExternalDocs{URL: matches[0][1], Description: strings.TrimSpace(matches[0][2])}
+		}
+		if rest := strings.TrimRight(externalDocsRegex.ReplaceAllString(line, ""), " \t"); strings.TrimSpace(rest) != "" {
+			lines = append(lines, rest)
+		}
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n")), link
+}
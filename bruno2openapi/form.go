@@ -0,0 +1,72 @@
+package bruno2openapi
+
+import (
+	"regexp"
+	"strings"
+)
+
+type formField struct {
+	name  string
+	value string
+}
+
+// parseFormFields reads the key: value lines of a form body block in
+// order, skipping disabled (~) fields.
+func parseFormFields(body string) []formField {
+	fields := []formField{}
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "~") {
+			continue
+		}
+		if k, v := splitKeyValue(line); k != "" {
+			fields = append(fields, formField{name: k, value: v})
+		}
+	}
+	return fields
+}
+
+// buildFormBody documents a body:form-urlencoded block as an object with
+// one string property per field.
+func buildFormBody(req Request) *RequestBody {
+	schema := &MediaSchema{Type: "object", Properties: map[string]*MediaSchema{}}
+	example := map[string]any{}
+	for _, field := range parseFormFields(req.Body) {
+		schema.Properties[field.name] = &MediaSchema{Type: "string"}
+		example[field.name] = field.value
+	}
+	return &RequestBody{
+		Required: true,
+		Content: map[string]MediaType{
+			"application/x-www-form-urlencoded": {Schema: schema, Example: example},
+		},
+	}
+}
+
+// contentTypeAnnotationRegex matches the @contentType(...) annotation of
+// a body:file entry.
+var contentTypeAnnotationRegex = regexp.MustCompile(`@contentType\(\s*([^()]*?)\s*\)`)
+
+// buildFileBody documents a body:file block, whose enabled entry is sent
+// as the raw request body, as a binary upload. An explicit Content-Type
+// header sets the media type; otherwise it comes from the entry's
+// @contentType and defaults to application/octet-stream.
+func buildFileBody(req Request) *RequestBody {
+	contentType := "application/octet-stream"
+	if fields := parseFormFields(req.Body); len(fields) > 0 {
+		if m := contentTypeAnnotationRegex.FindStringSubmatch(fields[0].value); m != nil && m[1] != "" {
+			contentType = m[1]
+		}
+	}
+	for name, value := range req.Headers {
+		if strings.EqualFold(name, "Content-Type") && value != "" {
+			contentType, _ = splitContentType(value, false)
+		}
+	}
+	return &RequestBody{
+		Required: true,
+		Content: map[string]MediaType{
+			contentType: {Schema: &MediaSchema{Type: "string", Format: "binary"}},
+		},
+	}
+}
@@ -0,0 +1,83 @@
+package bruno2openapi
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// addCorpusSeeds seeds f with every .bru file of the golden corpus.
+func addCorpusSeeds(f *testing.F) {
+	filepath.WalkDir(filepath.Join("testdata", "corpus"), func(path string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() && strings.HasSuffix(path, ".bru") {
+			if content, err := os.ReadFile(path); err == nil {
+				f.Add(string(content))
+			}
+		}
+		return nil
+	})
+}
+
+func FuzzParseBru(f *testing.F) {
+	addCorpusSeeds(f)
+	f.Add("body:json {\n  {\n")
+	f.Add("meta {\n  tags: [\n")
+	f.Add(strings.Repeat("{", 1000))
+	f.Add(strings.Repeat("a:", 1000) + " {")
+	f.Fuzz(func(t *testing.T, content string) {
+		req, err := parseBru(content)
+		if err != nil {
+			return
+		}
+		if req.Headers == nil || req.Query == nil || req.PathParams == nil {
+			t.Fatalf("parsed request has nil maps: %+v", req)
+		}
+	})
+}
+
+func FuzzSplitKeyValue(f *testing.F) {
+	for _, seed := range []string{"url: {{baseUrl}}/users", "name:", ":", "a:b:c", "  key  :  value  ", "", `"a:b": "c\"d"`, `'x`} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, line string) {
+		k, _ := splitKeyValue(line)
+		trimmed := strings.TrimSpace(line)
+		quoted := strings.HasPrefix(trimmed, `"`) || strings.HasPrefix(trimmed, "'")
+		if !quoted && strings.Contains(k, ":") {
+			t.Fatalf("unquoted key %q contains a colon", k)
+		}
+	})
+}
+
+func FuzzSplitURL(f *testing.F) {
+	for _, seed := range []string{"{{baseUrl}}/users/:id", "https://API.example.com:443/v1", "http://[::1", "users", "", "{{", "}}{{"} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, raw string) {
+		splitURL(raw)
+	})
+}
+
+func FuzzExtractQueryFromURL(f *testing.F) {
+	for _, seed := range []string{"/users?page=1&size=10", "/a?b", "/a?=x&&y=", "?", "/a?%zz=1", ""} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, raw string) {
+		cleaned, query := extractQueryFromURL(raw)
+		if query == nil {
+			t.Fatal("nil query map")
+		}
+		if len(query) > 0 && strings.Contains(cleaned, "?") {
+			t.Fatalf("query left in %q", cleaned)
+		}
+	})
+}
+
+func TestParseBruRejectsOversizedInput(t *testing.T) {
+	_, err := ParseBru(strings.NewReader(strings.Repeat("x", MaxFileSize+1)))
+	if err == nil || !strings.Contains(err.Error(), ErrFileTooLarge.Error()) {
+		t.Errorf("expected a size limit error, got %v", err)
+	}
+}
@@ -0,0 +1,100 @@
+package bruno2openapi
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// WarnDuplicateHeader is reported when a request sets the same header
+// under different casings with different values.
+const WarnDuplicateHeader = "duplicate-header"
+
+// canonicalHeaders merges headers whose names differ only by case (or by
+// the disabled ~ prefix) and renames them to their canonical form,
+// content-type becoming Content-Type. An enabled entry wins over a
+// disabled one; among several enabled entries the first by name is kept
+// and, when their values differ, a warning is returned.
+func canonicalHeaders(headers map[string]string, file string) (map[string]string, []Warning) {
+	type entry struct {
+		name     string
+		disabled bool
+	}
+	groups := map[string][]entry{}
+	order := []string{}
+	for _, name := range sortedKeys(headers) {
+		bare, disabled := strings.CutPrefix(name, "~")
+		key := http.CanonicalHeaderKey(bare)
+		if _, ok := groups[key]; !ok {
+			order = append(order, key)
+		}
+		groups[key] = append(groups[key], entry{name: name, disabled: disabled})
+	}
+
+	out := make(map[string]string, len(groups))
+	warnings := []Warning{}
+	for _, key := range order {
+		var winner *entry
+		values := map[string]bool{}
+		for i, e := range groups[key] {
+			if !e.disabled {
+				values[headers[e.name]] = true
+			}
+			if winner == nil || (winner.disabled && !e.disabled) {
+				winner = &groups[key][i]
+			}
+		}
+		name := key
+		if winner.disabled {
+			name = "~" + key
+		}
+		out[name] = headers[winner.name]
+		if len(values) > 1 {
+			warnings = append(warnings, Warning{
+				Code:    WarnDuplicateHeader,
+				File:    file,
+				Key:     key,
+				Message: fmt.Sprintf("header set more than once with different values; using %q", headers[winner.name]),
+			})
+		}
+	}
+	return out, warnings
+}
+
+// cookieParameters documents the name=value pairs of the request's Cookie
+// header as cookie parameters. It returns nil when opts.KeepCookieHeader
+// is set, in which case the header is documented as-is.
+func cookieParameters(req Request, opts Options) []Parameter {
+	if opts.KeepCookieHeader {
+		return nil
+	}
+	parameters := []Parameter{}
+	for _, e := range keyValueEntries(req.Headers, opts.IncludeDisabled) {
+		if !strings.EqualFold(e.name, "cookie") {
+			continue
+		}
+		for _, pair := range strings.Split(e.value, ";") {
+			name, value, _ := strings.Cut(strings.TrimSpace(pair), "=")
+			if name = strings.TrimSpace(name); name == "" || hasParam(parameters, name) {
+				continue
+			}
+			parameters = append(parameters, Parameter{
+				Name:        name,
+				In:          "cookie",
+				Description: e.description(),
+				Schema:      Schema{Type: "string"},
+				Example:     strings.Trim(strings.TrimSpace(value), `"`),
+			})
+		}
+	}
+	return parameters
+}
+
+func hasParam(parameters []Parameter, name string) bool {
+	for _, p := range parameters {
+		if p.Name == name {
+			return true
+		}
+	}
+	return false
+}
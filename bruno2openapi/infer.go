@@ -0,0 +1,87 @@
+package bruno2openapi
+
+import (
+	"net/url"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+var (
+	integerRegex = regexp.MustCompile(`^-?(0|[1-9][0-9]*)$`)
+	numberRegex  = regexp.MustCompile(`^-?(0|[1-9][0-9]*)\.[0-9]+$`)
+	uuidRegex    = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	emailRegex   = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s.]+$`)
+)
+
+// inferScalar guesses the schema type of an example value, including the
+// date, date-time, uuid, email and uri string formats. Numbers with
+// leading zeros (zip codes, ids like 007) stay strings. value is the
+// example converted to the inferred type; typ is empty when nothing
+// better than a plain string was found.
+func inferScalar(s string) (typ, format string, value any) {
+	switch {
+	case s == "true" || s == "false":
+		return "boolean", "", s == "true"
+	case integerRegex.MatchString(s):
+		if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return "integer", "", n
+		}
+	case numberRegex.MatchString(s):
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return "number", "", f
+		}
+	}
+	if _, err := time.Parse(time.DateOnly, s); err == nil {
+		return "string", "date", s
+	}
+	if _, err := time.Parse(time.RFC3339, s); err == nil {
+		return "string", "date-time", s
+	}
+	if format := stringFormat(s); format != "" {
+		return "string", format, s
+	}
+	return "", "", s
+}
+
+// stringFormat recognizes uuid, email and uri values. Only absolute URLs
+// with a host count as uri, so paths and plain words stay unformatted.
+func stringFormat(s string) string {
+	switch {
+	case uuidRegex.MatchString(s):
+		return "uuid"
+	case emailRegex.MatchString(s):
+		return "email"
+	}
+	if u, err := url.Parse(s); err == nil && u.Scheme != "" && u.Host != "" {
+		return "uri"
+	}
+	return ""
+}
+
+// inferQueryTypes types query parameters from their examples, so 42
+// becomes an integer and 2024-01-01 a date. Parameters documented as an
+// enum, or whose default disagrees with the example, stay strings.
+func inferQueryTypes(parameters []Parameter) {
+	for i := range parameters {
+		p := &parameters[i]
+		example, ok := p.Example.(string)
+		if p.In != "query" || !ok || len(p.Schema.Enum) > 0 {
+			continue
+		}
+		typ, format, value := inferScalar(example)
+		if typ == "" {
+			continue
+		}
+		if def, ok := p.Schema.Default.(string); ok {
+			defType, defFormat, defValue := inferScalar(def)
+			if defType != typ || defFormat != format {
+				continue
+			}
+			p.Schema.Default = defValue
+		}
+		p.Schema.Type = typ
+		p.Schema.Format = format
+		p.Example = value
+	}
+}
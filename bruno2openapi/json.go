@@ -0,0 +1,92 @@
+package bruno2openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// MarshalJSON encodes doc as pretty-printed JSON. It goes through the same
+// node tree as MarshalYAML, so keys come out in the same order.
+func MarshalJSON(doc OpenAPI) ([]byte, error) {
+	return marshalDocumentJSON(doc, doc.PathOrder)
+}
+
+// MarshalSwaggerJSON encodes a Swagger 2.0 document like MarshalJSON.
+func MarshalSwaggerJSON(doc Swagger) ([]byte, error) {
+	return marshalDocumentJSON(doc, doc.PathOrder)
+}
+
+func marshalDocumentJSON(doc any, pathOrder []string) ([]byte, error) {
+	node, err := documentNode(doc, pathOrder)
+	if err != nil {
+		return nil, err
+	}
+	var compact bytes.Buffer
+	if err := writeJSON(&compact, node); err != nil {
+		return nil, err
+	}
+	var out bytes.Buffer
+	if err := json.Indent(&out, compact.Bytes(), "", "  "); err != nil {
+		return nil, err
+	}
+	out.WriteByte('\n')
+	return out.Bytes(), nil
+}
+
+// writeJSON writes a YAML node tree as compact JSON, keeping mapping keys
+// in order. Scalars are decoded by their resolved tag, so "200" stays a
+// string and 200 a number.
+func writeJSON(buf *bytes.Buffer, n *yaml.Node) error {
+	switch n.Kind {
+	case yaml.DocumentNode:
+		if len(n.Content) == 0 {
+			buf.WriteString("null")
+			return nil
+		}
+		return writeJSON(buf, n.Content[0])
+	case yaml.MappingNode:
+		buf.WriteByte('{')
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			key, err := json.Marshal(n.Content[i].Value)
+			if err != nil {
+				return err
+			}
+			buf.Write(key)
+			buf.WriteByte(':')
+			if err := writeJSON(buf, n.Content[i+1]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	case yaml.SequenceNode:
+		buf.WriteByte('[')
+		for i, child := range n.Content {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeJSON(buf, child); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case yaml.ScalarNode:
+		var v any
+		if err := n.Decode(&v); err != nil {
+			return err
+		}
+		out, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("encoding %q as JSON: %w", n.Value, err)
+		}
+		buf.Write(out)
+	case yaml.AliasNode:
+		return writeJSON(buf, n.Alias)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package bruno2openapi
+
+import "strings"
+
+// relaxJSON rewrites the JSON5-ish bodies Bruno users keep around into
+// strict JSON: // and /* */ comments are dropped, as are commas right
+// before a closing } or ]. String contents are left alone.
+func relaxJSON(text string) string {
+	var b strings.Builder
+	inString := false
+	// pendingComma holds a comma that is written only if something other
+	// than a closing bracket follows it.
+	pendingComma := false
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		if inString {
+			b.WriteByte(c)
+			if c == '\\' && i+1 < len(text) {
+				i++
+				b.WriteByte(text[i])
+			} else if c == '"' {
+				inString = false
+			}
+			continue
+		}
+		switch {
+		case c == '/' && i+1 < len(text) && text[i+1] == '/':
+			for i < len(text) && text[i] != '\n' {
+				i++
+			}
+			if i < len(text) {
+				b.WriteByte('\n')
+			}
+			continue
+		case c == '/' && i+1 < len(text) && text[i+1] == '*':
+			end := strings.Index(text[i+2:], "*/")
+			if end < 0 {
+				i = len(text)
+			} else {
+				i += end + 3
+			}
+			continue
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			b.WriteByte(c)
+			continue
+		case c == ',':
+			if pendingComma {
+				b.WriteByte(',')
+			}
+			pendingComma = true
+			continue
+		}
+		if pendingComma && c != '}' && c != ']' {
+			b.WriteByte(',')
+		}
+		pendingComma = false
+		if c == '"' {
+			inString = true
+		}
+		b.WriteByte(c)
+	}
+	if pendingComma {
+		b.WriteByte(',')
+	}
+	return b.String()
+}
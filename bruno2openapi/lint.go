@@ -0,0 +1,71 @@
+package bruno2openapi
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	WarnMetaStatus        = "meta-status"
+	WarnInvalidSunset     = "invalid-sunset"
+	WarnSunsetPassed      = "sunset-passed"
+	WarnUnknownParamsType = "unknown-params-type"
+	WarnSyntax            = "syntax"
+)
+
+// now is replaced in tests.
+var now = time.Now
+
+// Lint returns the request's parse warnings followed by the findings of
+// every per-request lint rule.
+func Lint(req Request) []Warning {
+	warnings := append([]Warning{}, req.Warnings...)
+	warnings = append(warnings, DetectSecrets(req)...)
+	warnings = append(warnings, lintMetaStatus(req)...)
+	warnings = append(warnings, lintSunset(req)...)
+	return warnings
+}
+
+// lintSunset flags requests whose sunset date has passed but that are
+// still part of the collection.
+func lintSunset(req Request) []Warning {
+	if req.Sunset == "" {
+		return nil
+	}
+	sunset, err := time.Parse(time.DateOnly, req.Sunset)
+	if err != nil || !now().After(sunset.AddDate(0, 0, 1)) {
+		return nil
+	}
+	return []Warning{{
+		Code:    WarnSunsetPassed,
+		File:    req.File,
+		Key:     "meta sunset",
+		Message: fmt.Sprintf("sunset date %s has passed; remove the request or move the date", req.Sunset),
+	}}
+}
+
+// lintMetaStatus flags meta status values that are not a success (2xx) or
+// redirect (3xx) code.
+func lintMetaStatus(req Request) []Warning {
+	if req.Status == "" {
+		return nil
+	}
+	code, ok := parseStatusCode(req.Status)
+	if !ok {
+		return []Warning{{
+			Code:    WarnMetaStatus,
+			File:    req.File,
+			Key:     "meta status",
+			Message: fmt.Sprintf("%q is not an HTTP status code and is ignored", req.Status),
+		}}
+	}
+	if code < 200 || code > 399 {
+		return []Warning{{
+			Code:    WarnMetaStatus,
+			File:    req.File,
+			Key:     "meta status",
+			Message: fmt.Sprintf("%d is not a 2xx/3xx success code", code),
+		}}
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package bruno2openapi
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLintMetaStatus(t *testing.T) {
+	tests := []struct {
+		status string
+		warn   bool
+	}{
+		{"", false},
+		{"201", false},
+		{"304", false},
+		{"404", true},
+		{"100", true},
+		{"created", true},
+	}
+	for _, tt := range tests {
+		got := lintMetaStatus(Request{File: "a.bru", Status: tt.status})
+		if (len(got) > 0) != tt.warn {
+			t.Errorf("status %q: got warnings %v, want warn=%v", tt.status, got, tt.warn)
+		}
+	}
+}
+
+func TestLintSunset(t *testing.T) {
+	defer func(orig func() time.Time) { now = orig }(now)
+	now = func() time.Time { return time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC) }
+
+	if got := lintSunset(Request{Sunset: "2025-06-30"}); len(got) != 1 || got[0].Code != WarnSunsetPassed {
+		t.Errorf("expected a sunset-passed warning, got %v", got)
+	}
+	if got := lintSunset(Request{Sunset: "2025-07-01"}); len(got) != 0 {
+		t.Errorf("sunset today must not warn, got %v", got)
+	}
+}
+
+func TestParseSunset(t *testing.T) {
+	for in, want := range map[string]string{"2025-06-30": "2025-06-30", "2025-06-30T10:00:00Z": "2025-06-30", "'2025-06-30'": "2025-06-30"} {
+		if got, ok := parseSunset(in); !ok || got != want {
+			t.Errorf("parseSunset(%q) = %q, %v", in, got, ok)
+		}
+	}
+	if _, ok := parseSunset("30/06/2025"); ok {
+		t.Error("expected an invalid date")
+	}
+}
@@ -0,0 +1,73 @@
+package bruno2openapi
+
+import (
+	"regexp"
+	"strings"
+)
+
+// partHeaderRegex matches a per-part header annotation appended to a
+// multipart field, e.g. `file: @file(a.png) @header(X-Checksum: abc)`.
+var partHeaderRegex = regexp.MustCompile(`\s*@header\(\s*([^:()]+?)\s*:\s*([^()]*?)\s*\)`)
+
+// fileFieldRegex matches a file attachment, @file(a.png) or several files
+// separated by |.
+var fileFieldRegex = regexp.MustCompile(`^@file\((.*)\)$`)
+
+type multipartField struct {
+	formField
+	headers map[string]string
+}
+
+// parseMultipartFields reads the fields of a body:multipart-form block in
+// order, stripping any @header(...) annotations from their values.
+func parseMultipartFields(body string) []multipartField {
+	fields := []multipartField{}
+	for _, f := range parseFormFields(body) {
+		field := multipartField{formField: f, headers: map[string]string{}}
+		for _, m := range partHeaderRegex.FindAllStringSubmatch(f.value, -1) {
+			field.headers[m[1]] = m[2]
+		}
+		field.value = strings.TrimSpace(partHeaderRegex.ReplaceAllString(f.value, ""))
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+// buildMultipartBody documents a body:multipart-form block as
+// multipart/form-data. @file(...) fields are binary strings (an array of
+// them for several files); annotated part headers go under encoding and
+// fields without annotations get no encoding entry.
+func buildMultipartBody(req Request) *RequestBody {
+	schema := &MediaSchema{Type: "object", Properties: map[string]*MediaSchema{}}
+	example := map[string]any{}
+	encoding := map[string]Encoding{}
+	for _, field := range parseMultipartFields(req.Body) {
+		if m := fileFieldRegex.FindStringSubmatch(field.value); m != nil {
+			// File contents have no meaningful example.
+			binary := &MediaSchema{Type: "string", Format: "binary"}
+			if strings.Contains(m[1], "|") {
+				binary = &MediaSchema{Type: "array", Items: binary}
+			}
+			schema.Properties[field.name] = binary
+		} else {
+			schema.Properties[field.name] = &MediaSchema{Type: "string"}
+			example[field.name] = field.value
+		}
+		if len(field.headers) == 0 {
+			continue
+		}
+		headers := map[string]Header{}
+		for name, value := range field.headers {
+			headers[name] = Header{Schema: &Schema{Type: "string"}, Example: value}
+		}
+		encoding[field.name] = Encoding{Headers: headers}
+	}
+	media := MediaType{Schema: schema, Example: example}
+	if len(encoding) > 0 {
+		media.Encoding = encoding
+	}
+	return &RequestBody{
+		Required: true,
+		Content:  map[string]MediaType{"multipart/form-data": media},
+	}
+}
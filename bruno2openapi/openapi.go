@@ -0,0 +1,441 @@
+// Package bruno2openapi converts Bruno (.bru) request collections into
+// OpenAPI 3 documents.
+//
+// A typical conversion parses a collection with CollectRequests and feeds
+// the result to Build:
+//
+//	requests, err := bruno2openapi.CollectRequests(os.DirFS(dir), ".")
+//	if err != nil { ... }
+//	doc, err := bruno2openapi.Build(requests, bruno2openapi.Options{})
+package bruno2openapi
+
+// Request is a single parsed .bru file.
+type Request struct {
+	Method     string
+	URL        string
+	Headers    map[string]string
+	Query      map[string]string
+	PathParams map[string]string
+	Body       string
+	BodyType   string
+	// GraphQLVars holds the raw body:graphql:vars block of a GraphQL
+	// request; the query itself is in Body.
+	GraphQLVars string
+	// Vars holds the vars:pre-request block. Its values resolve {{name}}
+	// placeholders in the request's examples.
+	Vars map[string]string
+	// PostResponseVars holds the vars:post-response block: variables the
+	// request captures from its response (token: res.body.token). The
+	// values are expressions, not examples.
+	PostResponseVars map[string]string
+	// Asserts holds the assert block, expression to assertion
+	// (res.status: eq 200).
+	Asserts map[string]string
+	// Seq is the meta seq, the request's position within its folder; 0
+	// when unset.
+	Seq int
+	// Tests holds the raw script of the tests block.
+	Tests string
+	// Scripts holds the script:pre-request and script:post-response
+	// blocks keyed by phase; nil when the request has none.
+	Scripts map[string]string
+	// Settings holds the raw settings block (encodeUrl, timeout, ...);
+	// nil when the request has none.
+	Settings map[string]string
+	// Auth is the request's auth block, nil when it has none or the
+	// method block sets auth: none. CollectRequests fills it in from the
+	// nearest folder for auth: inherit.
+	Auth *Auth
+	// AuthMode is the auth value of the method block (bearer, none,
+	// inherit, ...); empty when not given.
+	AuthMode string
+	// FolderVars are the pre-request vars declared by folder.bru files of
+	// the folders containing this request, nearest folder first.
+	FolderVars []FolderVar
+	// Folders holds the folder.bru metadata of the folders containing
+	// this request, nearest folder first.
+	Folders     []Folder
+	Name        string
+	Tag         string
+	Description string
+	// Enums holds the allowed values from @enum(name: a|b) annotations in
+	// the docs block, which are removed from Description.
+	Enums map[string][]string
+	// ExternalDocs is the link of a @docs(url, description) annotation in
+	// the docs block.
+	ExternalDocs *ExternalDocs
+	// Extensions holds the raw values of x- keys from the meta and openapi
+	// blocks, copied onto the operation as vendor extensions.
+	Extensions map[string]string
+	// Examples are the saved responses of the request's example blocks.
+	Examples []ResponseExample
+	// Tags lists the meta tags of the request.
+	Tags []string
+	// Ignore is set by `meta { ignore: true }`.
+	Ignore bool
+	// Skipped is set by CollectRequests for a file it could not parse; the
+	// request then carries only its File and a WarnSyntax warning.
+	Skipped bool
+	// FolderFile is set on the entry CollectRequests returns for each
+	// folder.bru and collection.bru, so that warnings about the file are
+	// reported once, against it.
+	FolderFile bool
+	// Deprecated is set by `meta { deprecated: true }` or the
+	// DeprecatedTag meta tag.
+	Deprecated bool
+	// Sunset is the RFC 3339 full-date from `meta { sunset: ... }`.
+	Sunset string
+	// Warnings are non-fatal problems found while parsing the file.
+	Warnings []Warning
+	// Status is the raw `meta { status: ... }` value, an explicit success
+	// response code for the operation.
+	Status string
+	// File is the slash-separated path of the source .bru file relative
+	// to the collection root. It is empty for requests parsed with ParseBru.
+	File string
+}
+
+// IgnoreTag is the reserved meta tag that, like `meta { ignore: true }`,
+// keeps a request out of the generated spec.
+const IgnoreTag = "no-docs"
+
+// DeprecatedTag is the reserved meta tag that, like
+// `meta { deprecated: true }`, marks the operation deprecated.
+const DeprecatedTag = "deprecated"
+
+// IsDeprecated reports whether the request, or a folder containing it, is
+// marked deprecated.
+func (r Request) IsDeprecated() bool {
+	if r.Deprecated {
+		return true
+	}
+	for _, tag := range r.Tags {
+		if tag == DeprecatedTag {
+			return true
+		}
+	}
+	for _, f := range r.Folders {
+		if f.Deprecated {
+			return true
+		}
+	}
+	return false
+}
+
+// Excluded reports whether the request is kept out of the generated spec.
+func (r Request) Excluded() bool {
+	if r.Ignore || r.Skipped || r.FolderFile {
+		return true
+	}
+	for _, tag := range r.Tags {
+		if tag == IgnoreTag {
+			return true
+		}
+	}
+	return false
+}
+
+// Auth is a parsed auth:<mode> block, e.g. auth:bearer { token: ... }.
+type Auth struct {
+	Mode   string
+	Values map[string]string
+}
+
+// Folder is the metadata a folder.bru file gives its folder, or the root
+// collection.bru (Path ".") gives the collection.
+type Folder struct {
+	// Path is the slash-separated folder path relative to the collection
+	// root.
+	Path        string
+	Name        string
+	Description string
+	// Headers are sent with every request below the folder.
+	Headers map[string]string
+	// Auth and AuthMode come from the folder's auth { mode } and
+	// auth:<mode> blocks.
+	Auth     *Auth
+	AuthMode string
+	// Deprecated marks every request below the folder deprecated.
+	Deprecated bool
+	// ExternalDocs is the @docs link of the folder's docs.
+	ExternalDocs *ExternalDocs
+}
+
+// FolderVar is a variable declared in a folder.bru vars:pre-request block.
+type FolderVar struct {
+	Name  string
+	Value string
+	// Folder is the slash-separated folder path, "." for the collection root.
+	Folder string
+}
+
+type OpenAPI struct {
+	OpenAPI    string               `yaml:"openapi"`
+	Info       Info                 `yaml:"info"`
+	Servers    []Server             `yaml:"servers,omitempty"`
+	Tags       []Tag                `yaml:"tags,omitempty"`
+	Paths      map[string]*PathItem `yaml:"paths"`
+	Components *Components          `yaml:"components,omitempty"`
+	// Security is the requirement of the collection-level auth, which
+	// operations follow unless they list their own.
+	Security []SecurityRequirement `yaml:"security,omitempty"`
+	// ExternalDocs comes from a @docs annotation in collection.bru.
+	ExternalDocs *ExternalDocs `yaml:"externalDocs,omitempty"`
+	// Extensions holds document-level x- extensions such as x-tagGroups.
+	Extensions map[string]any `yaml:",inline"`
+
+	// Sources maps path and method to the .bru file that produced the
+	// operation. It is not part of the serialized document.
+	Sources map[string]map[string]string `yaml:"-"`
+	// Warnings collects non-fatal findings made while building.
+	Warnings []Warning `yaml:"-"`
+	// PathOrder, when set, is the order MarshalYAML emits paths in
+	// instead of sorting them.
+	PathOrder []string `yaml:"-"`
+}
+
+// PathItem holds the operations of one path keyed by lowercase method,
+// plus path-level x- extensions.
+type PathItem struct {
+	Operations map[string]Operation
+	Extensions map[string]any
+	// Order, when set, lists the methods in the order they are emitted;
+	// otherwise operations follow methodOrder.
+	Order []string
+}
+
+// methodOrder is the conventional order of the operations of a path item:
+// reads, then writes from create to delete, then the probe methods.
+var methodOrder = []string{"get", "post", "put", "patch", "delete", "options", "head", "trace"}
+
+// methods returns the methods of p in emission order.
+func (p PathItem) methods() []string {
+	return orderMethods(p.Order, sortedKeys(p.Operations))
+}
+
+// orderMethods returns order when set, otherwise methods arranged by
+// methodOrder followed by any other methods sorted by name.
+func orderMethods(order, methods []string) []string {
+	if len(order) > 0 {
+		return order
+	}
+	present := map[string]bool{}
+	for _, method := range methods {
+		present[method] = true
+	}
+	out := []string{}
+	for _, method := range methodOrder {
+		if present[method] {
+			out = append(out, method)
+		}
+	}
+	for _, method := range methods {
+		if !openAPIMethods[method] {
+			out = append(out, method)
+		}
+	}
+	return out
+}
+
+func (p PathItem) MarshalYAML() (any, error) {
+	operations := make(map[string]any, len(p.Operations))
+	for method, op := range p.Operations {
+		operations[method] = op
+	}
+	return orderedMapping(p.methods(), operations, p.Extensions)
+}
+
+type Info struct {
+	Title          string   `yaml:"title"`
+	Description    string   `yaml:"description,omitempty"`
+	TermsOfService string   `yaml:"termsOfService,omitempty"`
+	Contact        *Contact `yaml:"contact,omitempty"`
+	License        *License `yaml:"license,omitempty"`
+	Version        string   `yaml:"version"`
+}
+
+type Contact struct {
+	Name  string `yaml:"name,omitempty" json:"name"`
+	URL   string `yaml:"url,omitempty" json:"url"`
+	Email string `yaml:"email,omitempty" json:"email"`
+}
+
+// License names the API's license, e.g. an SPDX identifier such as
+// Apache-2.0, with an optional URL to its text.
+type License struct {
+	Name string `yaml:"name" json:"name"`
+	URL  string `yaml:"url,omitempty" json:"url"`
+}
+
+type Server struct {
+	URL         string                    `yaml:"url"`
+	Description string                    `yaml:"description,omitempty"`
+	Variables   map[string]ServerVariable `yaml:"variables,omitempty"`
+}
+
+// ServerVariable is a {name} substitution in a server URL.
+type ServerVariable struct {
+	Default     string   `yaml:"default"`
+	Enum        []string `yaml:"enum,omitempty"`
+	Description string   `yaml:"description,omitempty"`
+}
+
+type Components struct {
+	Schemas         map[string]*MediaSchema   `yaml:"schemas,omitempty"`
+	SecuritySchemes map[string]SecurityScheme `yaml:"securitySchemes,omitempty"`
+}
+
+type SecurityScheme struct {
+	Type         string      `yaml:"type"`
+	Description  string      `yaml:"description,omitempty"`
+	Scheme       string      `yaml:"scheme,omitempty"`
+	BearerFormat string      `yaml:"bearerFormat,omitempty"`
+	Name         string      `yaml:"name,omitempty"`
+	In           string      `yaml:"in,omitempty"`
+	Flows        *OAuthFlows `yaml:"flows,omitempty"`
+}
+
+type OAuthFlows struct {
+	Implicit          *OAuthFlow `yaml:"implicit,omitempty"`
+	Password          *OAuthFlow `yaml:"password,omitempty"`
+	ClientCredentials *OAuthFlow `yaml:"clientCredentials,omitempty"`
+	AuthorizationCode *OAuthFlow `yaml:"authorizationCode,omitempty"`
+}
+
+type OAuthFlow struct {
+	AuthorizationURL string            `yaml:"authorizationUrl,omitempty"`
+	TokenURL         string            `yaml:"tokenUrl,omitempty"`
+	RefreshURL       string            `yaml:"refreshUrl,omitempty"`
+	Scopes           map[string]string `yaml:"scopes"`
+}
+
+// SecurityRequirement maps security scheme names to required scopes.
+type SecurityRequirement map[string][]string
+
+// SecurityRequirements lists alternative requirements of an operation. An
+// empty, non-nil list is written as security: [] and lifts the document's
+// global requirement; nil follows it.
+type SecurityRequirements []SecurityRequirement
+
+// IsZero reports whether s is left out of the document.
+func (s SecurityRequirements) IsZero() bool {
+	return s == nil
+}
+
+type Tag struct {
+	Name         string        `yaml:"name"`
+	Description  string        `yaml:"description,omitempty"`
+	ExternalDocs *ExternalDocs `yaml:"externalDocs,omitempty"`
+}
+
+// ExternalDocs links to documentation outside the spec.
+type ExternalDocs struct {
+	Description string `yaml:"description,omitempty"`
+	URL         string `yaml:"url"`
+}
+
+// TagGroup is an entry of the x-tagGroups extension Redoc uses to nest
+// tags under a heading.
+type TagGroup struct {
+	Name string   `yaml:"name"`
+	Tags []string `yaml:"tags"`
+}
+
+type Operation struct {
+	OperationID string `yaml:"operationId,omitempty"`
+	Summary     string `yaml:"summary,omitempty"`
+	Description string `yaml:"description,omitempty"`
+	// ExternalDocs comes from a @docs annotation in the request's docs.
+	ExternalDocs *ExternalDocs        `yaml:"externalDocs,omitempty"`
+	Tags         []string             `yaml:"tags,omitempty"`
+	Parameters   []Parameter          `yaml:"parameters,omitempty"`
+	RequestBody  *RequestBody         `yaml:"requestBody,omitempty"`
+	Responses    map[string]Response  `yaml:"responses"`
+	Deprecated   bool                 `yaml:"deprecated,omitempty"`
+	Security     SecurityRequirements `yaml:"security,omitempty"`
+	// Servers overrides the document servers for an operation on a host
+	// other than the collection's main one.
+	Servers []Server `yaml:"servers,omitempty"`
+	// Extensions holds x- vendor extensions such as x-sunset.
+	Extensions map[string]any `yaml:",inline"`
+}
+
+func (op *Operation) setExtension(name string, value any) {
+	if op.Extensions == nil {
+		op.Extensions = map[string]any{}
+	}
+	op.Extensions[name] = value
+}
+
+type Parameter struct {
+	Name        string `yaml:"name"`
+	In          string `yaml:"in"`
+	Description string `yaml:"description,omitempty"`
+	Required    bool   `yaml:"required"`
+	Schema      Schema `yaml:"schema"`
+	Example     any    `yaml:"example,omitempty"`
+}
+
+type Schema struct {
+	Type    string   `yaml:"type,omitempty"`
+	Format  string   `yaml:"format,omitempty"`
+	Enum    []string `yaml:"enum,omitempty"`
+	Default any      `yaml:"default,omitempty"`
+}
+
+type RequestBody struct {
+	Required bool                 `yaml:"required"`
+	Content  map[string]MediaType `yaml:"content"`
+}
+
+type MediaType struct {
+	Schema  *MediaSchema `yaml:"schema,omitempty"`
+	Example any          `yaml:"example,omitempty"`
+	// Examples holds named examples, used instead of Example when several
+	// requests document the same operation.
+	Examples map[string]Example  `yaml:"examples,omitempty"`
+	Encoding map[string]Encoding `yaml:"encoding,omitempty"`
+}
+
+type Example struct {
+	Summary string `yaml:"summary,omitempty"`
+	Value   any    `yaml:"value"`
+}
+
+// Encoding describes a single multipart part.
+type Encoding struct {
+	ContentType string            `yaml:"contentType,omitempty"`
+	Headers     map[string]Header `yaml:"headers,omitempty"`
+}
+
+type Header struct {
+	Description string  `yaml:"description,omitempty"`
+	Schema      *Schema `yaml:"schema,omitempty"`
+	Example     any     `yaml:"example,omitempty"`
+}
+
+type MediaSchema struct {
+	// Ref points at a shared schema in components/schemas; the other
+	// fields are empty when it is set.
+	Ref        string                  `yaml:"$ref,omitempty"`
+	Type       string                  `yaml:"type,omitempty"`
+	Format     string                  `yaml:"format,omitempty"`
+	Items      *MediaSchema            `yaml:"items,omitempty"`
+	Properties map[string]*MediaSchema `yaml:"properties,omitempty"`
+	Required   []string                `yaml:"required,omitempty"`
+	OneOf      []*MediaSchema          `yaml:"oneOf,omitempty"`
+	Enum       []any                   `yaml:"enum,omitempty"`
+	XML        *XMLObject              `yaml:"xml,omitempty"`
+	Nullable   bool                    `yaml:"nullable,omitempty"`
+	Example    any                     `yaml:"example,omitempty"`
+}
+
+type XMLObject struct {
+	Name string `yaml:"name,omitempty"`
+}
+
+type Response struct {
+	Description string               `yaml:"description"`
+	Headers     map[string]Header    `yaml:"headers,omitempty"`
+	Content     map[string]MediaType `yaml:"content,omitempty"`
+}
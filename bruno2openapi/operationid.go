@@ -0,0 +1,140 @@
+package bruno2openapi
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Operation ID styles accepted by Options.OperationIDStyle.
+const (
+	OperationIDCamel = "camel"
+	OperationIDSnake = "snake"
+	OperationIDKebab = "kebab"
+)
+
+// Operation ID strategies accepted by Options.OperationIDStrategy.
+const (
+	OperationIDRequestName   = "requestName"
+	OperationIDMethodPath    = "methodPath"
+	OperationIDFolderAndName = "folderAndName"
+)
+
+// operationIDs hands out unique operationIds in a single style. Collisions
+// get a numeric suffix in the order requests are built, which is the
+// (sorted) collection walk order, so the result is stable across runs.
+type operationIDs struct {
+	style    string
+	strategy string
+	used     map[string]bool
+}
+
+func newOperationIDs(style, strategy string) (*operationIDs, error) {
+	switch style {
+	case "":
+		style = OperationIDCamel
+	case OperationIDCamel, OperationIDSnake, OperationIDKebab:
+	default:
+		return nil, fmt.Errorf("unknown operation id style %q (want camel, snake or kebab)", style)
+	}
+	switch strategy {
+	case "":
+		strategy = OperationIDRequestName
+	case OperationIDRequestName, OperationIDMethodPath, OperationIDFolderAndName:
+	default:
+		return nil, fmt.Errorf("unknown operation id strategy %q (want %s, %s or %s)", strategy, OperationIDRequestName, OperationIDMethodPath, OperationIDFolderAndName)
+	}
+	return &operationIDs{style: style, strategy: strategy, used: map[string]bool{}}, nil
+}
+
+// next derives an operationId following the strategy: from the request
+// name, from the method and path, or from the folder followed by the
+// request name. Requests without a meaningful name always fall back to
+// the method and path. Only letters and digits survive, and an id that
+// would start with a digit is prefixed with the method.
+func (ids *operationIDs) next(req Request, pathName string) string {
+	words := identifierWords(req.Name)
+	if ids.strategy == OperationIDMethodPath || !hasName(req) || len(words) == 0 {
+		words = append([]string{req.Method}, identifierWords(pathName)...)
+	}
+	if ids.strategy == OperationIDFolderAndName {
+		words = append(identifierWords(req.Tag), words...)
+	}
+	if r := []rune(words[0]); unicode.IsDigit(r[0]) {
+		words = append([]string{req.Method}, words...)
+	}
+
+	base := ids.join(words)
+	id := base
+	for n := 2; ids.used[id]; n++ {
+		id = ids.join(append(words, strconv.Itoa(n)))
+	}
+	ids.used[id] = true
+	return id
+}
+
+func (ids *operationIDs) join(words []string) string {
+	switch ids.style {
+	case OperationIDSnake:
+		return strings.Join(words, "_")
+	case OperationIDKebab:
+		return strings.Join(words, "-")
+	}
+	var sb strings.Builder
+	for i, w := range words {
+		if i == 0 {
+			sb.WriteString(w)
+			continue
+		}
+		r := []rune(w)
+		r[0] = unicode.ToUpper(r[0])
+		sb.WriteString(string(r))
+	}
+	return sb.String()
+}
+
+// identifierWords splits s into lowercase words on non-alphanumeric
+// characters and lower-to-upper case transitions ("getUserByID" and
+// "Get user by ID" both yield get, user, by, id).
+func identifierWords(s string) []string {
+	words := []string{}
+	current := []rune{}
+	flush := func() {
+		if len(current) > 0 {
+			words = append(words, strings.ToLower(string(current)))
+			current = current[:0]
+		}
+	}
+	var prev rune
+	for _, r := range s {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			flush()
+			prev = 0
+			continue
+		}
+		if unicode.IsUpper(r) && (unicode.IsLower(prev) || unicode.IsDigit(prev)) {
+			flush()
+		}
+		current = append(current, r)
+		prev = r
+	}
+	flush()
+	return words
+}
+
+// hasName reports whether the request has a meta name of its own rather
+// than none or the parser's "Unnamed" placeholder.
+func hasName(req Request) bool {
+	name := strings.TrimSpace(req.Name)
+	return name != "" && name != "Unnamed"
+}
+
+// operationSummary is the request's name, or "GET /users/{id}" when it
+// has none.
+func operationSummary(req Request, pathName string) string {
+	if hasName(req) {
+		return strings.TrimSpace(req.Name)
+	}
+	return strings.ToUpper(req.Method) + " " + pathName
+}
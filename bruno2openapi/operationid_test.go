@@ -0,0 +1,77 @@
+package bruno2openapi
+
+import "testing"
+
+func TestOperationIDStyles(t *testing.T) {
+	tests := []struct {
+		style string
+		want  []string
+	}{
+		{OperationIDCamel, []string{"getUserById", "getUserById2", "deleteUsersId"}},
+		{OperationIDSnake, []string{"get_user_by_id", "get_user_by_id_2", "delete_users_id"}},
+		{OperationIDKebab, []string{"get-user-by-id", "get-user-by-id-2", "delete-users-id"}},
+	}
+	for _, tt := range tests {
+		ids, err := newOperationIDs(tt.style, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := []string{
+			ids.next(Request{Method: "get", Name: "Get user by ID"}, "/users/{id}"),
+			ids.next(Request{Method: "get", Name: "getUserByID"}, "/users/{id}"),
+			ids.next(Request{Method: "delete", Name: "Unnamed"}, "/users/{id}"),
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("%s: got %q, want %q", tt.style, got[i], tt.want[i])
+			}
+		}
+	}
+}
+
+func TestOperationIDStrategies(t *testing.T) {
+	requests := []Request{
+		{Method: "get", Name: "Get user", Tag: "admin/users"},
+		{Method: "post", Name: "2FA Login", Tag: "auth"},
+		{Method: "get", Name: "Get user", Tag: "admin/users"},
+		{Method: "delete", Name: "Unnamed", Tag: "admin/users"},
+	}
+	paths := []string{"/users/{id}", "/auth/2fa", "/v2/users/{id}", "/users/{id}"}
+	tests := []struct {
+		strategy string
+		want     []string
+	}{
+		{OperationIDRequestName, []string{"getUser", "post2FaLogin", "getUser2", "deleteUsersId"}},
+		{OperationIDMethodPath, []string{"getUsersId", "postAuth2fa", "getV2UsersId", "deleteUsersId"}},
+		{OperationIDFolderAndName, []string{"adminUsersGetUser", "auth2FaLogin", "adminUsersGetUser2", "adminUsersDeleteUsersId"}},
+	}
+	for _, tt := range tests {
+		ids, err := newOperationIDs(OperationIDCamel, tt.strategy)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, req := range requests {
+			if got := ids.next(req, paths[i]); got != tt.want[i] {
+				t.Errorf("%s: got %q, want %q", tt.strategy, got, tt.want[i])
+			}
+		}
+	}
+}
+
+func TestOperationIDUnknownStyle(t *testing.T) {
+	if _, err := Build(nil, Options{OperationIDStyle: "pascal"}); err == nil {
+		t.Error("expected an error for an unknown style")
+	}
+	if _, err := Build(nil, Options{OperationIDStrategy: "random"}); err == nil {
+		t.Error("expected an error for an unknown strategy")
+	}
+}
+
+func TestTruncateTag(t *testing.T) {
+	if got := truncateTag("admin/users/roles", 1); got != "admin" {
+		t.Errorf("got %q", got)
+	}
+	if got := truncateTag("admin/users/roles", 0); got != "admin/users/roles" {
+		t.Errorf("got %q", got)
+	}
+}
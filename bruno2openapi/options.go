@@ -0,0 +1,324 @@
+package bruno2openapi
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Options controls how Build assembles the OpenAPI document. The zero
+// value behaves like NewDefaultOptions: empty fields take their defaults.
+type Options struct {
+	// Title and Version populate the info block; empty values fall back
+	// to DefaultTitle and DefaultVersion.
+	Title   string
+	Version string
+	// Description is the info description; when empty, the docs of
+	// collection.bru are used.
+	Description string
+	// TermsOfService, Contact and License fill the matching info fields
+	// when set; a Contact without any field and a License without a name
+	// are left out.
+	TermsOfService string
+	Contact        *Contact
+	License        *License
+	// GraphQLContentType selects how body:graphql requests are documented:
+	// "application/json" (the default) wraps query and variables in a JSON
+	// object as sent by GraphQL-over-HTTP clients, "application/graphql"
+	// keeps the raw query text.
+	GraphQLContentType string
+	// OperationIDStyle is one of OperationIDCamel (the default),
+	// OperationIDSnake or OperationIDKebab.
+	OperationIDStyle string
+	// OperationIDStrategy is OperationIDRequestName (the default),
+	// OperationIDMethodPath or OperationIDFolderAndName.
+	OperationIDStrategy string
+	// TagDepth limits folder-derived tags to their first TagDepth path
+	// segments, so "admin/users" becomes "admin" with TagDepth 1. Zero
+	// keeps the full folder path as a single tag.
+	TagDepth int
+	// TagStrategy is TagStrategyPath (the default), which tags operations
+	// with their slash-joined folder path (admin/users/roles), or
+	// TagStrategyGroups, which tags them with their folder's name (roles)
+	// and lists the tags under their top-level folder in x-tagGroups.
+	TagStrategy string
+	// HeaderIgnore lists extra header names (case-insensitive) that are not
+	// documented as header parameters, e.g. ones injected by a gateway.
+	HeaderIgnore []string
+	// FoldProbeMethods folds HEAD and OPTIONS requests into their sibling
+	// operations instead of emitting them: HEAD only confirms the GET, and
+	// an OPTIONS preflight becomes x-cors metadata on the path item.
+	FoldProbeMethods bool
+	// Variables, when set, resolves {{name}} placeholders in paths,
+	// servers and examples. Secret values are redacted from examples and
+	// become path template parameters, but still resolve server URLs.
+	// Placeholders no variable resolves become path parameters as well.
+	Variables *Variables
+	// Environments, when set, replace the document's server with one
+	// server per environment: the unresolved server most requests use
+	// ({{baseUrl}}) rendered with the environment's variables and
+	// described by its name. Placeholders nested in a value or missing
+	// from the environment become server variables.
+	Environments []Environment
+	// BasePathMode decides where the path part of a resolved base URL
+	// (baseUrl = https://host/api/v1) goes: BasePathInServer (the default)
+	// keeps it on the server URL, BasePathInPath prefixes it to every path.
+	BasePathMode string
+	// DescriptionTemplate, when set, synthesizes a description for
+	// operations without a docs block, e.g. "Performs {method} on {path}.".
+	// See DescriptionTokens for the available tokens. Generated
+	// descriptions are marked with x-generated-description: true.
+	DescriptionTemplate string
+	// Ordering is OrderByPath (the default), which sorts paths and tags
+	// and emits methods in the conventional order (get, post, put, patch,
+	// delete, options, head, trace), or OrderBySeq, which follows the
+	// collection: folders in order, requests by meta seq within a folder.
+	Ordering string
+	// EmitScripts copies script:pre-request and script:post-response
+	// blocks into an x-bruno-scripts extension for downstream tooling.
+	EmitScripts bool
+	// Unresolved decides what happens to {{name}} placeholders in
+	// parameter, header and body examples that no variable resolves:
+	// UnresolvedKeep (the default) leaves them, UnresolvedBlank removes
+	// them and UnresolvedError makes Build fail listing them.
+	Unresolved string
+	// BodyRequired is BodyRequiredAuto (the default), which requires the
+	// body of POST, PUT and PATCH requests unless it is empty ({} or []),
+	// or BodyRequiredAlways or BodyRequiredNever.
+	BodyRequired string
+	// RequiredProperties decides the required list of object schemas
+	// inferred from JSON bodies: RequiredPropertiesNone (the default),
+	// RequiredPropertiesAll, every property seen in an example, or
+	// RequiredPropertiesIntersection, the properties present in every
+	// example of the operation (and in every element of an array).
+	RequiredProperties string
+	// SkipUnexpectedBodies leaves the bodies of GET, HEAD and DELETE
+	// requests out of the spec, with a warning, instead of documenting them.
+	SkipUnexpectedBodies bool
+	// KeepContentTypeParams keeps parameters such as charset=utf-8 in
+	// request body media type keys; by default only the base type is used.
+	KeepContentTypeParams bool
+	// KeepCookieHeader documents a Cookie header as a header parameter
+	// instead of splitting it into one cookie parameter per name=value
+	// pair.
+	KeepCookieHeader bool
+	// ExtraMethods lists non-standard methods (purge, link, ...) to
+	// document as x-<method> path item extensions. Requests with other
+	// unknown methods are skipped with a warning.
+	ExtraMethods []string
+	// BaseURL is the server of requests whose URL is relative (/users).
+	// When empty, a baseUrl variable, if defined, stands in for it.
+	BaseURL string
+	// TrailingSlash is TrailingSlashStrip (the default), which documents
+	// /users/ as /users, or TrailingSlashKeep. Repeated slashes are always
+	// collapsed.
+	TrailingSlash string
+	// OnConflict decides what happens when two requests map to the same
+	// path and method: OnConflictMerge (the default) documents one
+	// operation with the union of their parameters and a named body
+	// example per request, OnConflictKeepFirst keeps the first request,
+	// OnConflictSuffix documents the later one as an x-get-2 style path
+	// item extension whose x-bruno-duplicate-of names the first operation,
+	// and OnConflictError makes Build fail. Each collision is reported as
+	// a WarnDuplicateOperation warning.
+	OnConflict string
+	// NoTypeInference keeps every query parameter a string instead of
+	// inferring integer, number, boolean, date and date-time schemas from
+	// the example values.
+	NoTypeInference bool
+	// IncludeDisabled documents disabled (~-prefixed) headers, query and
+	// path params instead of dropping them, described as disabled.
+	IncludeDisabled bool
+}
+
+// Values accepted by Options.BasePathMode.
+const (
+	BasePathInServer = "server"
+	BasePathInPath   = "path"
+)
+
+const (
+	DefaultTitle   = "API from Bruno"
+	DefaultVersion = "1.0.0"
+)
+
+// Values accepted by Options.TagStrategy.
+const (
+	TagStrategyPath   = "path"
+	TagStrategyGroups = "groups"
+)
+
+// OtherTagGroup is the x-tagGroups entry of tags that do not come from a
+// folder.
+const OtherTagGroup = "Other"
+
+// Values accepted by Options.Ordering.
+const (
+	OrderByPath = "path"
+	OrderBySeq  = "seq"
+)
+
+// Values accepted by Options.Unresolved.
+const (
+	UnresolvedKeep  = "keep"
+	UnresolvedBlank = "blank"
+	UnresolvedError = "error"
+)
+
+// Values accepted by Options.BodyRequired.
+const (
+	BodyRequiredAuto   = "auto"
+	BodyRequiredAlways = "always"
+	BodyRequiredNever  = "never"
+)
+
+// Values accepted by Options.RequiredProperties.
+const (
+	RequiredPropertiesNone         = "none"
+	RequiredPropertiesAll          = "all"
+	RequiredPropertiesIntersection = "intersection"
+)
+
+// Values accepted by Options.TrailingSlash.
+const (
+	TrailingSlashStrip = "strip"
+	TrailingSlashKeep  = "keep"
+)
+
+// Values accepted by Options.OnConflict.
+const (
+	OnConflictKeepFirst = "keep-first"
+	OnConflictMerge     = "merge"
+	OnConflictSuffix    = "suffix"
+	OnConflictError     = "error"
+)
+
+// Values accepted by Options.GraphQLContentType.
+const (
+	GraphQLAsJSON = "application/json"
+	GraphQLAsRaw  = "application/graphql"
+)
+
+// NewDefaultOptions returns the options the CLI starts from before
+// applying flags, with every default spelled out.
+func NewDefaultOptions() Options {
+	return Options{
+		Title:               DefaultTitle,
+		Version:             DefaultVersion,
+		GraphQLContentType:  GraphQLAsJSON,
+		OperationIDStyle:    OperationIDCamel,
+		OperationIDStrategy: OperationIDRequestName,
+		TagStrategy:         TagStrategyPath,
+		BasePathMode:        BasePathInServer,
+		Ordering:            OrderByPath,
+		Unresolved:          UnresolvedKeep,
+		OnConflict:          OnConflictMerge,
+		TrailingSlash:       TrailingSlashStrip,
+		BodyRequired:        BodyRequiredAuto,
+		RequiredProperties:  RequiredPropertiesNone,
+	}
+}
+
+// ConflictError reports two options that cannot be used together.
+type ConflictError struct {
+	Option, Other string
+	Reason        string
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("options %s and %s conflict: %s", e.Option, e.Other, e.Reason)
+}
+
+// Validate checks each option value, then returns a *ConflictError for
+// combinations that cannot be honored together. Build calls it first, so
+// embedding applications get the same errors as the CLI.
+func (o Options) Validate() error {
+	if _, err := newOperationIDs(o.OperationIDStyle, o.OperationIDStrategy); err != nil {
+		return err
+	}
+	if o.GraphQLContentType != "" && o.GraphQLContentType != GraphQLAsJSON && o.GraphQLContentType != GraphQLAsRaw {
+		return fmt.Errorf("unknown GraphQL content type %q (want %s or %s)", o.GraphQLContentType, GraphQLAsJSON, GraphQLAsRaw)
+	}
+	if o.BasePathMode != "" && o.BasePathMode != BasePathInServer && o.BasePathMode != BasePathInPath {
+		return fmt.Errorf("unknown base path mode %q (want server or path)", o.BasePathMode)
+	}
+	if o.TagStrategy != "" && o.TagStrategy != TagStrategyPath && o.TagStrategy != TagStrategyGroups {
+		return fmt.Errorf("unknown tag strategy %q (want %s or %s)", o.TagStrategy, TagStrategyPath, TagStrategyGroups)
+	}
+	if o.Ordering != "" && o.Ordering != OrderByPath && o.Ordering != OrderBySeq {
+		return fmt.Errorf("unknown ordering %q (want %s or %s)", o.Ordering, OrderByPath, OrderBySeq)
+	}
+	if o.Unresolved != "" && o.Unresolved != UnresolvedKeep && o.Unresolved != UnresolvedBlank && o.Unresolved != UnresolvedError {
+		return fmt.Errorf("unknown unresolved variable mode %q (want %s, %s or %s)", o.Unresolved, UnresolvedKeep, UnresolvedBlank, UnresolvedError)
+	}
+	if o.BodyRequired != "" && o.BodyRequired != BodyRequiredAuto && o.BodyRequired != BodyRequiredAlways && o.BodyRequired != BodyRequiredNever {
+		return fmt.Errorf("unknown body required mode %q (want %s, %s or %s)", o.BodyRequired, BodyRequiredAuto, BodyRequiredAlways, BodyRequiredNever)
+	}
+	switch o.RequiredProperties {
+	case "", RequiredPropertiesNone, RequiredPropertiesAll, RequiredPropertiesIntersection:
+	default:
+		return fmt.Errorf("unknown required properties mode %q (want %s, %s or %s)", o.RequiredProperties, RequiredPropertiesNone, RequiredPropertiesAll, RequiredPropertiesIntersection)
+	}
+	if o.TrailingSlash != "" && o.TrailingSlash != TrailingSlashStrip && o.TrailingSlash != TrailingSlashKeep {
+		return fmt.Errorf("unknown trailing slash mode %q (want %s or %s)", o.TrailingSlash, TrailingSlashStrip, TrailingSlashKeep)
+	}
+	switch o.OnConflict {
+	case "", OnConflictKeepFirst, OnConflictMerge, OnConflictSuffix, OnConflictError:
+	default:
+		return fmt.Errorf("unknown conflict strategy %q (want %s, %s, %s or %s)", o.OnConflict, OnConflictKeepFirst, OnConflictMerge, OnConflictSuffix, OnConflictError)
+	}
+	if err := checkDescriptionTemplate(o.DescriptionTemplate); err != nil {
+		return err
+	}
+	if o.TagDepth < 0 {
+		return fmt.Errorf("tag depth must not be negative, got %d", o.TagDepth)
+	}
+	return o.checkConflicts()
+}
+
+// checkConflicts reports the first pair of options that cannot be honored
+// together.
+func (o Options) checkConflicts() error {
+	if o.FoldProbeMethods {
+		for _, method := range o.ExtraMethods {
+			if method = strings.ToLower(strings.TrimSpace(method)); method == "head" || method == "options" {
+				return &ConflictError{
+					Option: "FoldProbeMethods",
+					Other:  "ExtraMethods",
+					Reason: fmt.Sprintf("%s requests are folded into their sibling operations and cannot also be documented as x-%s", method, method),
+				}
+			}
+		}
+	}
+	if o.BasePathMode == BasePathInPath {
+		for _, env := range o.Environments {
+			for _, name := range sortedKeys(env.Vars) {
+				if p := urlPath(env.Vars[name]); p != "" {
+					return &ConflictError{
+						Option: "BasePathMode",
+						Other:  "Environments",
+						Reason: fmt.Sprintf("environment %s puts %s in %s, but paths can carry only one base path; keep it on the servers", env.Name, p, name),
+					}
+				}
+			}
+		}
+	}
+	if o.TagStrategy == TagStrategyGroups && o.TagDepth == 1 {
+		return &ConflictError{
+			Option: "TagStrategy",
+			Other:  "TagDepth",
+			Reason: "with one folder level every tag is its own group; use a deeper tag depth or the path strategy",
+		}
+	}
+	return nil
+}
+
+// baseURL returns the server for relative request URLs.
+func (o Options) baseURL() string {
+	if o.BaseURL != "" {
+		return o.BaseURL
+	}
+	if o.Variables.Has("baseUrl") {
+		return "{{baseUrl}}"
+	}
+	return ""
+}
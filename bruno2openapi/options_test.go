@@ -0,0 +1,94 @@
+package bruno2openapi
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDefaultOptionsMatchZeroValue(t *testing.T) {
+	requests := []Request{
+		{Method: "post", URL: "{{baseUrl}}/graphql", Name: "Search", Body: "{ users { id } }", BodyType: "graphql"},
+		{Method: "get", URL: "https://api.example.com/v1/users/:id", Name: "Get User"},
+	}
+	zero, err := Build(requests, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defaults, err := Build(requests, NewDefaultOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(zero, defaults) {
+		t.Errorf("NewDefaultOptions output differs from zero Options:\n%+v\n%+v", defaults, zero)
+	}
+}
+
+func TestOptionsValidate(t *testing.T) {
+	if err := NewDefaultOptions().Validate(); err != nil {
+		t.Fatalf("defaults invalid: %v", err)
+	}
+	opts := NewDefaultOptions()
+	opts.GraphQLContentType = "text/plain"
+	if err := opts.Validate(); err == nil || !strings.Contains(err.Error(), "text/plain") {
+		t.Errorf("unknown GraphQL content type accepted: %v", err)
+	}
+}
+
+func TestOptionsValidateConflicts(t *testing.T) {
+	prod := Environment{Name: "prod", Vars: map[string]string{"baseUrl": "https://{{region}}.api.example.com/v1/", "region": "eu"}}
+	bare := Environment{Name: "dev", Vars: map[string]string{"baseUrl": "http://localhost:8080/"}}
+	tests := []struct {
+		name          string
+		edit          func(*Options)
+		option, other string
+	}{
+		{"folded head as extra method", func(o *Options) {
+			o.FoldProbeMethods = true
+			o.ExtraMethods = []string{"purge", " HEAD"}
+		}, "FoldProbeMethods", "ExtraMethods"},
+		{"base path in paths with environment paths", func(o *Options) {
+			o.BasePathMode = BasePathInPath
+			o.Environments = []Environment{bare, prod}
+		}, "BasePathMode", "Environments"},
+		{"tag groups of one level", func(o *Options) {
+			o.TagStrategy = TagStrategyGroups
+			o.TagDepth = 1
+		}, "TagStrategy", "TagDepth"},
+		{"extra methods without folding", func(o *Options) {
+			o.ExtraMethods = []string{"head"}
+		}, "", ""},
+		{"base path in paths with host-only environments", func(o *Options) {
+			o.BasePathMode = BasePathInPath
+			o.Environments = []Environment{bare}
+		}, "", ""},
+		{"tag groups of two levels", func(o *Options) {
+			o.TagStrategy = TagStrategyGroups
+			o.TagDepth = 2
+		}, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := NewDefaultOptions()
+			tt.edit(&opts)
+			err := opts.Validate()
+			if tt.option == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			var conflict *ConflictError
+			if !errors.As(err, &conflict) {
+				t.Fatalf("want *ConflictError, got %v", err)
+			}
+			if conflict.Option != tt.option || conflict.Other != tt.other {
+				t.Errorf("conflict names %s and %s, want %s and %s", conflict.Option, conflict.Other, tt.option, tt.other)
+			}
+			if msg := err.Error(); !strings.Contains(msg, tt.option) || !strings.Contains(msg, tt.other) {
+				t.Errorf("error does not name both options: %s", msg)
+			}
+		})
+	}
+}
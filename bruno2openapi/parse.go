@@ -0,0 +1,584 @@
+package bruno2openapi
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseError reports malformed .bru syntax.
+type ParseError struct {
+	File string
+	Line int
+	Msg  string
+}
+
+func (e *ParseError) Error() string {
+	if e.File != "" {
+		return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Msg)
+	}
+	return fmt.Sprintf("line %d: %s", e.Line, e.Msg)
+}
+
+var sectionRegex = regexp.MustCompile(`^([\w-]+)((?::[\w-]+)*)\s*\{$`)
+var pathParamRegex = regexp.MustCompile(`\{([^{}/]+)\}`)
+
+// MaxFileSize bounds the size of a .bru document. Real requests are a few
+// kilobytes; anything larger is refused rather than parsed.
+const MaxFileSize = 8 << 20
+
+// ErrFileTooLarge is returned for documents larger than MaxFileSize.
+var ErrFileTooLarge = errors.New("file exceeds the .bru size limit")
+
+// ParseBru parses a single .bru document. A returned *ParseError carries
+// the offending line; its File is filled in by CollectRequests. Lines the
+// parser can skip, such as a header without a colon, are reported as
+// WarnSyntax warnings on the request instead.
+func ParseBru(r io.Reader) (Request, error) {
+	content, err := io.ReadAll(io.LimitReader(r, MaxFileSize+1))
+	if err != nil {
+		return Request{}, err
+	}
+	if len(content) > MaxFileSize {
+		return Request{}, fmt.Errorf("%w of %d bytes", ErrFileTooLarge, MaxFileSize)
+	}
+	return parseBru(decodeText(content))
+}
+
+func parseBru(content string) (Request, error) {
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	result := Request{
+		Method:           "get",
+		Headers:          map[string]string{},
+		Query:            map[string]string{},
+		PathParams:       map[string]string{},
+		Vars:             map[string]string{},
+		Asserts:          map[string]string{},
+		Name:             "Unnamed",
+		PostResponseVars: map[string]string{},
+	}
+
+	section := ""
+	sectionType := ""
+	buffer := []string{}
+	sectionLine := 0
+	bodyDepth := 0
+	listKey := ""
+	authMode := ""
+	block := ""
+
+	syntaxWarning := func(line int, msg string) {
+		result.Warnings = append(result.Warnings, Warning{Code: WarnSyntax, Line: line, Key: block, Message: msg})
+	}
+
+	isMethodBlock := func(name string) bool {
+		switch name {
+		case "get", "post", "put", "patch", "delete", "options", "head", "trace", "connect":
+			return true
+		default:
+			return false
+		}
+	}
+
+	flushBuffer := func() {
+		if section == "body" && len(buffer) > 0 {
+			raw := strings.TrimSpace(dedent(buffer))
+			if raw != "" && sectionType == "graphql:vars" {
+				result.GraphQLVars = raw
+			} else if raw != "" {
+				result.Body = raw
+			}
+		} else if section == "docs" && len(buffer) > 0 {
+			raw, enums := extractEnums(strings.TrimSpace(dedent(buffer)))
+			raw, result.ExternalDocs = extractExternalDocs(raw)
+			if raw != "" {
+				result.Description = raw
+			}
+			if enums != nil {
+				result.Enums = enums
+			}
+		} else if section == "tests" && len(buffer) > 0 {
+			result.Tests = strings.TrimSpace(dedent(buffer))
+		} else if section == "example" && len(buffer) > 0 {
+			if ex, ok := parseExample(buffer); ok {
+				result.Examples = append(result.Examples, ex)
+			}
+		} else if section == "script" && len(buffer) > 0 {
+			if result.Scripts == nil {
+				result.Scripts = map[string]string{}
+			}
+			result.Scripts[sectionType] = strings.TrimSpace(dedent(buffer))
+		}
+		buffer = []string{}
+	}
+
+	for i, rawLine := range lines {
+		line := strings.TrimSpace(rawLine)
+		if line == "" {
+			// Blank lines separate markdown paragraphs in docs.
+			if section == "docs" {
+				buffer = append(buffer, "")
+			}
+			continue
+		}
+
+		// Body, docs, tests, script and example content is free-form
+		// (GraphQL selections, nested JSON, JavaScript), so it is consumed
+		// before looking for block headers.
+		if section == "body" || section == "docs" || section == "tests" || section == "script" || section == "example" {
+			// JSON bodies may carry // comment lines, which Bruno strips
+			// before sending.
+			if section == "body" && sectionType == "json" && strings.HasPrefix(line, "//") {
+				continue
+			}
+			bodyDepth += braceDelta(rawLine, stringQuotes[section])
+			if bodyDepth <= 0 {
+				flushBuffer()
+				section = ""
+				sectionType = ""
+				bodyDepth = 0
+				continue
+			}
+			buffer = append(buffer, rawLine)
+			continue
+		}
+
+		if isComment(line) {
+			continue
+		}
+
+		if match := sectionRegex.FindStringSubmatch(line); match != nil {
+			flushBuffer()
+			sectionLine = i + 1
+			name := strings.ToLower(match[1])
+			typeName := strings.TrimPrefix(strings.ToLower(match[2]), ":")
+			block = name + strings.ToLower(match[2])
+
+			if isMethodBlock(name) {
+				section = "method"
+				sectionType = name
+				result.Method = name
+			} else if name == "meta" {
+				section = "meta"
+				sectionType = ""
+			} else if name == "headers" {
+				section = "headers"
+				sectionType = ""
+			} else if name == "query" {
+				section = "query"
+				sectionType = ""
+			} else if name == "params" {
+				// Untyped params blocks predate params:query and hold path
+				// params.
+				switch typeName {
+				case "query":
+					section = "params_query"
+				case "path", "":
+					section = "params"
+				default:
+					section = "ignore"
+					result.Warnings = append(result.Warnings, Warning{
+						Code:    WarnUnknownParamsType,
+						Line:    i + 1,
+						Key:     "params:" + typeName,
+						Message: fmt.Sprintf("unknown params block type %q (want path or query); block ignored", typeName),
+					})
+				}
+				sectionType = typeName
+			} else if name == "vars" && (typeName == "pre-request" || typeName == "post-response") {
+				section = "vars"
+				sectionType = typeName
+			} else if name == "body" {
+				section = "body"
+				sectionType = typeName
+				if typeName != "graphql:vars" {
+					result.BodyType = typeName
+				}
+				bodyDepth = 1
+			} else if name == "docs" || name == "tests" || name == "example" {
+				section = name
+				sectionType = ""
+				bodyDepth = 1
+			} else if name == "script" {
+				section = "script"
+				sectionType = typeName
+				bodyDepth = 1
+			} else if name == "settings" {
+				section = "settings"
+				sectionType = ""
+			} else if name == "openapi" {
+				section = "openapi"
+				sectionType = ""
+			} else if name == "assert" {
+				section = "assert"
+				sectionType = ""
+			} else if name == "auth" && typeName == "" {
+				// folder.bru and collection.bru: auth { mode: bearer }
+				section = "auth_mode"
+				sectionType = ""
+			} else if name == "auth" && typeName != "" {
+				section = "auth"
+				sectionType = typeName
+				result.Auth = &Auth{Mode: typeName, Values: map[string]string{}}
+			} else {
+				section = "ignore"
+				sectionType = ""
+				if typeName == "" {
+					// Possibly a non-standard method block such as purge
+					// { url: ... }; its url line decides.
+					sectionType = name
+				}
+			}
+			continue
+		}
+
+		if line == "}" {
+			if section == "" {
+				block = ""
+				syntaxWarning(i+1, "unmatched }")
+				continue
+			}
+			flushBuffer()
+			section = ""
+			sectionType = ""
+			bodyDepth = 0
+			continue
+		}
+
+		if section == "" {
+			block = ""
+			syntaxWarning(i+1, fmt.Sprintf("unexpected %q outside a block", line))
+			continue
+		}
+		if section != "ignore" && !(section == "meta" && listKey != "") && !strings.Contains(line, ":") {
+			syntaxWarning(i+1, fmt.Sprintf("expected key: value, got %q", line))
+			continue
+		}
+
+		switch section {
+		case "ignore":
+			if k, v := splitKeyValue(line); k == "url" && sectionType != "" && result.URL == "" {
+				section = "method"
+				result.Method = sectionType
+				setURL(&result, v)
+			}
+		case "meta":
+			// Multi-line lists such as "tags: [" ... "]".
+			if listKey != "" {
+				if line == "]" {
+					listKey = ""
+				} else if listKey == "tags" {
+					result.Tags = append(result.Tags, parseList(line)...)
+				}
+				continue
+			}
+			k, v := splitKeyValue(line)
+			if v == "[" {
+				listKey = k
+				continue
+			}
+			if k == "name" {
+				result.Name = v
+			} else if k == "method" {
+				result.Method = strings.ToLower(v)
+			} else if k == "url" {
+				setURL(&result, v)
+			} else if k == "status" {
+				result.Status = v
+			} else if isExtensionKey(k) {
+				if result.Extensions == nil {
+					result.Extensions = map[string]string{}
+				}
+				result.Extensions[k] = v
+			} else if k == "seq" {
+				result.Seq, _ = strconv.Atoi(v)
+			} else if k == "sunset" {
+				if date, ok := parseSunset(v); ok {
+					result.Sunset = date
+				} else {
+					result.Warnings = append(result.Warnings, Warning{
+						Code:    WarnInvalidSunset,
+						Line:    i + 1,
+						Key:     "meta sunset",
+						Message: fmt.Sprintf("%q is not a valid date (want YYYY-MM-DD or RFC 3339)", v),
+					})
+				}
+			} else if k == "deprecated" {
+				result.Deprecated = strings.EqualFold(v, "true")
+			} else if k == "ignore" {
+				result.Ignore = strings.EqualFold(v, "true")
+			} else if k == "tags" {
+				result.Tags = append(result.Tags, parseList(v)...)
+			}
+		case "method":
+			k, v := splitKeyValue(line)
+			if k == "url" {
+				setURL(&result, v)
+			} else if k == "auth" {
+				authMode = strings.ToLower(v)
+			}
+		case "headers":
+			k, v := splitKeyValue(line)
+			if k != "" {
+				result.Headers[k] = v
+			}
+		case "query":
+			k, v := splitKeyValue(line)
+			if k != "" {
+				result.Query[k] = v
+			}
+		case "params_query":
+			k, v := splitKeyValue(line)
+			if k != "" {
+				result.Query[k] = v
+			}
+		case "params":
+			k, v := splitKeyValue(line)
+			if k != "" {
+				result.PathParams[k] = v
+			}
+		case "vars":
+			k, v := splitKeyValue(line)
+			if k == "" || strings.HasPrefix(k, "~") {
+				continue
+			}
+			if sectionType == "post-response" {
+				result.PostResponseVars[k] = v
+			} else {
+				result.Vars[k] = v
+			}
+		case "assert":
+			k, v := splitKeyValue(line)
+			if k != "" {
+				result.Asserts[k] = v
+			}
+		case "settings":
+			k, v := splitKeyValue(line)
+			if k != "" {
+				if result.Settings == nil {
+					result.Settings = map[string]string{}
+				}
+				result.Settings[k] = v
+			}
+		case "openapi":
+			k, v := splitKeyValue(line)
+			if isExtensionKey(k) {
+				if result.Extensions == nil {
+					result.Extensions = map[string]string{}
+				}
+				result.Extensions[k] = v
+			} else if k != "" {
+				result.Warnings = append(result.Warnings, Warning{
+					Code:    WarnUnknownOpenAPIKey,
+					Line:    i + 1,
+					Key:     "openapi " + k,
+					Message: "only x- vendor extensions are copied from the openapi block; key ignored",
+				})
+			}
+		case "auth_mode":
+			if k, v := splitKeyValue(line); k == "mode" {
+				authMode = strings.ToLower(v)
+			}
+		case "auth":
+			k, v := splitKeyValue(line)
+			if k != "" {
+				result.Auth.Values[k] = v
+			}
+		}
+	}
+
+	if section != "" {
+		return result, &ParseError{Line: sectionLine, Msg: "block is never closed"}
+	}
+	flushBuffer()
+	result.AuthMode = authMode
+	if authMode == "none" {
+		result.Auth = nil
+	}
+	return result, nil
+}
+
+// stringQuotes lists, per free-form section, the quote characters whose
+// strings may hold braces that do not count towards nesting. Docs are
+// markdown, where quotes and apostrophes need not pair up.
+var stringQuotes = map[string]string{
+	"body":   `"`,
+	"tests":  `"'` + "`",
+	"script": `"'` + "`",
+	// Example blocks hold JSON response bodies.
+	"example": `"`,
+}
+
+// braceDelta returns how much line changes the brace depth, skipping
+// braces inside quoted strings and escaped characters. Strings do not
+// span lines, so an unpaired quote cannot swallow the rest of a block.
+func braceDelta(line, quotes string) int {
+	delta := 0
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case c == '\\':
+			i++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case strings.IndexByte(quotes, c) >= 0:
+			quote = c
+		case c == '{':
+			delta++
+		case c == '}':
+			delta--
+		}
+	}
+	return delta
+}
+
+// isComment reports whether a line outside free-form content is a // or
+// # comment.
+func isComment(line string) bool {
+	return strings.HasPrefix(line, "//") || strings.HasPrefix(line, "#")
+}
+
+// parseSunset accepts an RFC 3339 full-date or timestamp and returns it as
+// a full-date.
+func parseSunset(value string) (string, bool) {
+	value = strings.Trim(strings.TrimSpace(value), `"'`)
+	for _, layout := range []string{time.DateOnly, time.RFC3339} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.Format(time.DateOnly), true
+		}
+	}
+	return "", false
+}
+
+// parseList splits an inline "[a, b]" list (or a single list line) into
+// its trimmed items.
+func parseList(value string) []string {
+	value = strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(value), "["), "]")
+	items := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// dedent joins lines after removing the indentation they all share, which
+// Bruno adds to every line inside a block.
+func dedent(lines []string) string {
+	common := -1
+	for _, l := range lines {
+		if strings.TrimSpace(l) == "" {
+			continue
+		}
+		indent := len(l) - len(strings.TrimLeft(l, " \t"))
+		if common < 0 || indent < common {
+			common = indent
+		}
+	}
+	out := make([]string, len(lines))
+	for i, l := range lines {
+		if len(l) >= common && common > 0 {
+			out[i] = l[common:]
+		} else {
+			out[i] = strings.TrimLeft(l, " \t")
+		}
+	}
+	return strings.Join(out, "\n")
+}
+
+// splitKeyValue splits a "key: value" line at the first colon. A key in
+// double or single quotes may itself contain colons ("x-a:b": 1), and a
+// value entirely in quotes keeps its inner spacing; both are unquoted with
+// \", \', \\, \n and \t escapes resolved.
+func splitKeyValue(line string) (string, string) {
+	line = strings.TrimSpace(line)
+	var key, value string
+	if k, rest, ok := unquote(line); ok && strings.HasPrefix(strings.TrimSpace(rest), ":") {
+		key = k
+		value = strings.TrimPrefix(strings.TrimSpace(rest), ":")
+	} else {
+		key, value, _ = strings.Cut(line, ":")
+		key = strings.TrimSpace(key)
+	}
+	value = strings.TrimSpace(value)
+	if v, rest, ok := unquote(value); ok && rest == "" {
+		value = v
+	}
+	return key, value
+}
+
+// unquote reads the quoted string s starts with and returns its unescaped
+// content and whatever follows the closing quote. ok is false when s does
+// not start with a quote or the quote is never closed.
+func unquote(s string) (content, rest string, ok bool) {
+	if s == "" || (s[0] != '"' && s[0] != '\'') {
+		return "", "", false
+	}
+	quote := s[0]
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == quote:
+			return b.String(), s[i+1:], true
+		case c == '\\' && i+1 < len(s):
+			i++
+			switch s[i] {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			case '"', '\'', '\\':
+				b.WriteByte(s[i])
+			default:
+				b.WriteByte('\\')
+				b.WriteByte(s[i])
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", "", false
+}
+
+func setURL(req *Request, raw string) {
+	cleaned, query := extractQueryFromURL(raw)
+	req.URL = cleaned
+	for k, v := range query {
+		if _, exists := req.Query[k]; !exists {
+			req.Query[k] = v
+		}
+	}
+}
+
+func extractQueryFromURL(raw string) (string, map[string]string) {
+	trimmed := strings.TrimSpace(raw)
+	if trimmed == "" {
+		return raw, map[string]string{}
+	}
+	parts := strings.SplitN(trimmed, "?", 2)
+	if len(parts) < 2 {
+		return raw, map[string]string{}
+	}
+	query := map[string]string{}
+	values, err := url.ParseQuery(parts[1])
+	if err != nil {
+		return parts[0], query
+	}
+	for k, v := range values {
+		if len(v) > 0 {
+			query[k] = v[0]
+		} else {
+			query[k] = ""
+		}
+	}
+	return parts[0], query
+}
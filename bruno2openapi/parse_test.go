@@ -0,0 +1,463 @@
+package bruno2openapi
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestParseBru(t *testing.T) {
+	req, err := ParseBru(strings.NewReader(`meta {
+  name: Get User
+}
+
+get {
+  url: {{baseUrl}}/users/:id?expand=roles
+}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.Name != "Get User" || req.Method != "get" || req.URL != "{{baseUrl}}/users/:id" {
+		t.Errorf("unexpected request: %+v", req)
+	}
+	if req.Query["expand"] != "roles" {
+		t.Errorf("query not extracted from URL: %v", req.Query)
+	}
+}
+
+func TestCollectRequestsReportsFileAndLine(t *testing.T) {
+	fsys := fstest.MapFS{
+		"api/users/ok.bru":     {Data: []byte("get {\n  url: /users\n}\n")},
+		"api/users/broken.bru": {Data: []byte("meta {\n  name: Broken\n}\n\nbody:json {\n  {\n")},
+	}
+	requests, err := CollectRequests(fsys, "api")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var broken, ok *Request
+	for i := range requests {
+		switch requests[i].File {
+		case "users/broken.bru":
+			broken = &requests[i]
+		case "users/ok.bru":
+			ok = &requests[i]
+		}
+	}
+	if ok == nil || ok.Excluded() {
+		t.Fatalf("well-formed file not collected: %+v", requests)
+	}
+	if broken == nil || !broken.Excluded() || len(broken.Warnings) != 1 {
+		t.Fatalf("want the broken file skipped with one warning, got %+v", broken)
+	}
+	if w := broken.Warnings[0]; w.Code != WarnSyntax || w.File != "users/broken.bru" || w.Line != 5 {
+		t.Errorf("got %v, want a syntax warning at users/broken.bru:5", w)
+	}
+
+	doc, err := Build(requests, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Paths) != 1 || doc.Paths["/users"] == nil {
+		t.Errorf("got paths %v", sortedKeys(doc.Paths))
+	}
+}
+
+func TestCollectRequestsReportsFolderFileWarnings(t *testing.T) {
+	fsys := fstest.MapFS{
+		"users/folder.bru": {Data: []byte("meta {\n  name: Users\n}\nstray\n\nheaders {\n  X-Tenant: acme\n}\n")},
+		"users/list.bru":   {Data: []byte("get {\n  url: /users\n}\n")},
+		"users/get.bru":    {Data: []byte("get {\n  url: /users/:id\n}\n")},
+	}
+	requests, err := CollectRequests(fsys, ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	warnings := []Warning{}
+	for _, req := range requests {
+		warnings = append(warnings, Lint(req)...)
+		if req.File != "users/folder.bru" && req.Headers["X-Tenant"] != "acme" {
+			t.Errorf("%s did not inherit the folder header", req.File)
+		}
+	}
+	want := []Warning{{Code: WarnSyntax, File: "users/folder.bru", Line: 4, Message: `unexpected "stray" outside a block`}}
+	if !reflect.DeepEqual(warnings, want) {
+		t.Errorf("got warnings %v, want %v", warnings, want)
+	}
+
+	doc, err := Build(requests, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Paths) != 2 {
+		t.Errorf("folder file documented as a request: paths %v", sortedKeys(doc.Paths))
+	}
+}
+
+func TestCollectRequestsTagsFromFolders(t *testing.T) {
+	fsys := fstest.MapFS{
+		"root.bru":            {Data: []byte("get {\n  url: /\n}\n")},
+		"admin/users/get.bru": {Data: []byte("get {\n  url: /users\n}\n")},
+	}
+	requests, err := CollectRequests(fsys, ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tags := map[string]string{}
+	for _, req := range requests {
+		tags[req.File] = req.Tag
+	}
+	if tags["root.bru"] != "" || tags["admin/users/get.bru"] != "admin/users" {
+		t.Errorf("unexpected tags: %v", tags)
+	}
+}
+
+func TestParseBruMetaTagsAndIgnore(t *testing.T) {
+	req, err := ParseBru(strings.NewReader("meta {\n  name: A\n  tags: [smoke, no-docs]\n  ignore: false\n}\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(req.Tags) != 2 || req.Tags[1] != IgnoreTag || !req.Excluded() {
+		t.Errorf("inline tags not parsed: %+v", req)
+	}
+
+	req, err = ParseBru(strings.NewReader("meta {\n  name: B\n  tags: [\n    smoke\n  ]\n  ignore: true\n}\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(req.Tags) != 1 || req.Tags[0] != "smoke" || !req.Excluded() {
+		t.Errorf("multi-line tags or ignore not parsed: %+v", req)
+	}
+}
+
+func TestParseBruAuth(t *testing.T) {
+	req, err := ParseBru(strings.NewReader("get {\n  url: /me\n  auth: bearer\n}\n\nauth:bearer {\n  token: {{token}}\n}\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.Auth == nil || req.Auth.Mode != "bearer" || req.Auth.Values["token"] != "{{token}}" {
+		t.Errorf("auth block not parsed: %+v", req.Auth)
+	}
+
+	req, err = ParseBru(strings.NewReader("get {\n  url: /me\n  auth: none\n}\n\nauth:bearer {\n  token: stale\n}\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.Auth != nil {
+		t.Errorf("auth: none should drop the auth block, got %+v", req.Auth)
+	}
+}
+
+func TestParseBruParamsBlocks(t *testing.T) {
+	req, err := ParseBru(strings.NewReader("get {\n  url: /orders/:id\n}\n\nparams:path {\n  id: 7\n}\n\nparams:query {\n  page: 2\n}\n\nparams:cookie {\n  session: abc\n}\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.PathParams["id"] != "7" || req.Query["page"] != "2" {
+		t.Errorf("params blocks not parsed: path %v, query %v", req.PathParams, req.Query)
+	}
+	if _, ok := req.PathParams["session"]; ok {
+		t.Errorf("params:cookie treated as path params: %v", req.PathParams)
+	}
+	if len(req.Warnings) != 1 || req.Warnings[0].Code != WarnUnknownParamsType {
+		t.Errorf("want one %s warning, got %+v", WarnUnknownParamsType, req.Warnings)
+	}
+}
+
+func TestParseBruBracesInStrings(t *testing.T) {
+	req, err := ParseBru(strings.NewReader(`post {
+  url: /notes
+}
+
+body:json {
+  {
+    "note": "use { and } carefully",
+    "escaped": "a \" } b"
+  }
+}
+
+script:pre-request {
+  const open = '{';
+}
+
+headers {
+  X-After: yes
+}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(req.Body, "}") || !strings.Contains(req.Body, `"escaped"`) {
+		t.Errorf("body truncated: %q", req.Body)
+	}
+	if req.Scripts["pre-request"] != "const open = '{';" {
+		t.Errorf("unexpected script: %q", req.Scripts["pre-request"])
+	}
+	if req.Headers["X-After"] != "yes" {
+		t.Errorf("block after the body not parsed: %v", req.Headers)
+	}
+}
+
+func TestParseBruEncodings(t *testing.T) {
+	const doc = "meta {\n  name: Café\n}\n\nget {\n  url: /cafés\n}\n"
+	utf16le := []byte{0xFF, 0xFE}
+	utf16be := []byte{0xFE, 0xFF}
+	for _, r := range doc {
+		utf16le = append(utf16le, byte(r), byte(r>>8))
+		utf16be = append(utf16be, byte(r>>8), byte(r))
+	}
+	inputs := map[string][]byte{
+		"utf-8 bom":    append([]byte{0xEF, 0xBB, 0xBF}, doc...),
+		"utf-16le":     utf16le,
+		"utf-16be":     utf16be,
+		"windows-1252": []byte(strings.ReplaceAll(doc, "é", "\xe9")),
+	}
+	for name, input := range inputs {
+		req, err := ParseBru(bytes.NewReader(input))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if req.Name != "Café" || req.URL != "/cafés" {
+			t.Errorf("%s: got name %q, url %q", name, req.Name, req.URL)
+		}
+	}
+}
+
+func TestParseBruCustomMethod(t *testing.T) {
+	req, err := ParseBru(strings.NewReader("meta {\n  name: Purge Cache\n}\n\npurge {\n  url: /cache/:key\n  body: none\n  auth: none\n}\n\nsomething {\n  url: /ignored\n}\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.Method != "purge" || req.URL != "/cache/:key" || req.AuthMode != "none" {
+		t.Errorf("custom method block not parsed: %s %s (auth %q)", req.Method, req.URL, req.AuthMode)
+	}
+}
+
+func TestParseBruComments(t *testing.T) {
+	req, err := ParseBru(strings.NewReader(`# list orders
+post {
+  url: /orders
+  // body: none
+}
+
+headers {
+  # X-Debug: true
+  X-Tenant: acme
+}
+
+body:json {
+  {
+    // "draft": true,
+    "id": 1
+  }
+}
+
+docs {
+  # Orders
+}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(req.Headers) != 1 || req.Headers["X-Tenant"] != "acme" {
+		t.Errorf("comment parsed as a header: %v", req.Headers)
+	}
+	if strings.Contains(req.Body, "draft") {
+		t.Errorf("comment kept in JSON body: %q", req.Body)
+	}
+	if req.Description != "# Orders" {
+		t.Errorf("markdown heading in docs dropped: %q", req.Description)
+	}
+}
+
+func TestParseBruSyntaxWarnings(t *testing.T) {
+	req, err := ParseBru(strings.NewReader("get {\n  url: /orders\n}\n\nheaders {\n  X-Tenant acme\n}\nstray\n}\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Warning{
+		{Code: WarnSyntax, Line: 6, Key: "headers", Message: `expected key: value, got "X-Tenant acme"`},
+		{Code: WarnSyntax, Line: 8, Message: `unexpected "stray" outside a block`},
+		{Code: WarnSyntax, Line: 9, Message: "unmatched }"},
+	}
+	if !reflect.DeepEqual(req.Warnings, want) {
+		t.Errorf("got %+v\nwant %+v", req.Warnings, want)
+	}
+	if w := (Warning{Code: WarnSyntax, File: "a.bru", Line: 6, Key: "headers", Message: "m"}); w.String() != "[syntax] a.bru:6 (headers): m" {
+		t.Errorf("unexpected String(): %s", w)
+	}
+}
+
+func TestSplitKeyValue(t *testing.T) {
+	tests := []struct {
+		line, key, value string
+	}{
+		{"Authorization: Bearer a:b:c", "Authorization", "Bearer a:b:c"},
+		{"url: https://example.com:8443/v1", "url", "https://example.com:8443/v1"},
+		{`"x-a:b": 1`, "x-a:b", "1"},
+		{`'odd key' : value`, "odd key", "value"},
+		{`greeting: "  hi: \"there\"  "`, "greeting", `  hi: "there"  `},
+		{`res.body.name: eq "Ada"`, "res.body.name", `eq "Ada"`},
+		{`note: "unterminated`, "note", `"unterminated`},
+		{`path: "C:\\tmp\n"`, "path", "C:\\tmp\n"},
+		{"empty:", "empty", ""},
+	}
+	for _, tt := range tests {
+		key, value := splitKeyValue(tt.line)
+		if key != tt.key || value != tt.value {
+			t.Errorf("splitKeyValue(%q) = %q, %q; want %q, %q", tt.line, key, value, tt.key, tt.value)
+		}
+	}
+}
+
+func TestParseBruScriptBlocks(t *testing.T) {
+	req, err := ParseBru(strings.NewReader(`get {
+  url: /users
+}
+
+script:pre-request {
+  if (!bru.getVar("token")) {
+    bru.setVar("token", "x");
+  }
+}
+
+script:post-response {
+  bru.setVar("id", res.body.id);
+}
+
+headers {
+  X-Trace: on
+}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(req.Scripts["pre-request"], "if (!bru.getVar") || !strings.HasSuffix(req.Scripts["pre-request"], "}") {
+		t.Errorf("pre-request script not captured: %q", req.Scripts["pre-request"])
+	}
+	if req.Scripts["post-response"] != `bru.setVar("id", res.body.id);` {
+		t.Errorf("post-response script not captured: %q", req.Scripts["post-response"])
+	}
+	if req.Headers["X-Trace"] != "on" {
+		t.Errorf("block after scripts not parsed: %v", req.Headers)
+	}
+}
+
+func TestCollectRequestsHonorsBrunoJSONIgnore(t *testing.T) {
+	fsys := fstest.MapFS{
+		"bruno.json":             {Data: []byte(`{"version": "1", "name": "Shop", "ignore": ["node_modules", "drafts/old"]}`)},
+		"users.bru":              {Data: []byte("get {\n  url: /users\n}\n")},
+		"drafts/old/broken.bru":  {Data: []byte("get {\n")},
+		"drafts/new.bru":         {Data: []byte("get {\n  url: /new\n}\n")},
+		"node_modules/x/mod.bru": {Data: []byte("get {\n")},
+		".git/hooks/ignored.bru": {Data: []byte("get {\n  url: /git\n}\n")},
+	}
+	requests, err := CollectRequests(fsys, ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := []string{}
+	for _, req := range requests {
+		files = append(files, req.File)
+	}
+	// .git is only skipped by default; an explicit ignore list replaces it.
+	if strings.Join(files, ",") != ".git/hooks/ignored.bru,drafts/new.bru,users.bru" {
+		t.Errorf("unexpected files: %v", files)
+	}
+
+	cfg, err := ReadConfig(fstest.MapFS{"bruno.json": {Data: []byte(`{"version": "2", "name": "Shop"}`)}}, ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Name != "Shop" || len(cfg.Warnings()) != 1 || cfg.Warnings()[0].Code != WarnFormatVersion {
+		t.Errorf("unexpected config %+v, warnings %v", cfg, cfg.Warnings())
+	}
+}
+
+func TestParseBruEnumAnnotations(t *testing.T) {
+	req, err := ParseBru(strings.NewReader("get {\n  url: /orders\n}\n\ndocs {\n  Lists orders. @enum(status: open | shipped|)\n  @enum(sort: created_at|total) @enum(empty: )\n  Paged.\n}\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.Description != "Lists orders.\nPaged." {
+		t.Errorf("annotations left in description: %q", req.Description)
+	}
+	want := map[string][]string{"status": {"open", "shipped"}, "sort": {"created_at", "total"}}
+	if !reflect.DeepEqual(req.Enums, want) {
+		t.Errorf("got enums %v, want %v", req.Enums, want)
+	}
+
+	req.Method, req.URL, req.File = "get", "/orders", "orders.bru"
+	doc := buildOpenAPI([]Request{req}, Options{})
+	if len(doc.Warnings) != 2 || doc.Warnings[0].Code != WarnUnknownEnum {
+		t.Errorf("want unknown-enum warnings for sort and status, got %v", doc.Warnings)
+	}
+}
+
+func TestParseBruExamples(t *testing.T) {
+	src := "get {\n  url: /users\n}\n\nexample {\n  name: Empty\n  response: {\n    headers: {\n      X-Total: 0\n    }\n    status: 200\n    body: {\n      type: json\n      content: '''\n        {\"items\": []}\n      '''\n    }\n  }\n}\n"
+	req, err := ParseBru(strings.NewReader(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(req.Examples) != 1 {
+		t.Fatalf("got %d examples, want 1", len(req.Examples))
+	}
+	ex := req.Examples[0]
+	if ex.Name != "Empty" || ex.Status != 200 || ex.BodyType != "json" || ex.Headers["X-Total"] != "0" {
+		t.Errorf("unexpected example %+v", ex)
+	}
+	if strings.TrimSpace(ex.Body) != `{"items": []}` {
+		t.Errorf("body = %q", ex.Body)
+	}
+}
+
+func TestReadConfigOpenAPI(t *testing.T) {
+	fsys := fstest.MapFS{"bruno.json": {Data: []byte(`{"version": "1", "name": "Shop", "openapi": {"title": "Shop API", "version": "2.1.0", "description": "Public endpoints."}}`)}}
+	cfg, err := ReadConfig(fsys, ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := OpenAPIConfig{Title: "Shop API", Version: "2.1.0", Description: "Public endpoints."}
+	if cfg.OpenAPI != want {
+		t.Errorf("got %+v, want %+v", cfg.OpenAPI, want)
+	}
+}
+
+func TestParseBruOpenAPIBlock(t *testing.T) {
+	req, err := ParseBru(strings.NewReader("meta {\n  name: Search\n  x-rate-limit: 100\n}\n\nget {\n  url: /search\n}\n\nopenapi {\n  x-owner: team-catalog\n  summary: ignored\n}\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"x-rate-limit": "100", "x-owner": "team-catalog"}
+	if !reflect.DeepEqual(req.Extensions, want) {
+		t.Errorf("got extensions %v, want %v", req.Extensions, want)
+	}
+	if len(req.Warnings) != 1 || req.Warnings[0].Code != WarnUnknownOpenAPIKey || req.Warnings[0].Line != 12 {
+		t.Errorf("want one unknown-openapi-key warning on line 12, got %v", req.Warnings)
+	}
+}
+
+func TestCollectRequestsProgressCountsSkippedFiles(t *testing.T) {
+	fsys := fstest.MapFS{
+		"collection.bru":   {Data: []byte("headers {\n  X-Tenant: acme\n}\n")},
+		"users/folder.bru": {Data: []byte("meta {\n  name: Users\n")},
+		"users/list.bru":   {Data: []byte("get {\n  url: /users\n}\n")},
+		"users/get.bru":    {Data: []byte("get {\n  url: /users/:id\n")},
+	}
+	calls := []int{}
+	_, err := CollectRequestsProgress(fsys, ".", func(done, total int) {
+		if total != 4 {
+			t.Errorf("total = %d, want 4", total)
+		}
+		calls = append(calls, done)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(calls, []int{1, 2, 3, 4}) {
+		t.Errorf("progress calls %v, want 1 to 4", calls)
+	}
+}
@@ -0,0 +1,89 @@
+package bruno2openapi
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const WarnUnfoldedProbe = "unfolded-probe"
+
+type probeRequest struct {
+	path   string
+	server string
+	req    Request
+}
+
+func isProbeMethod(method string) bool {
+	return method == "head" || method == "options"
+}
+
+// foldProbe merges a HEAD or OPTIONS request into the operations already
+// documented for its path. Probes that have nothing to fold into are
+// emitted as regular operations with a warning.
+func (b *builder) foldProbe(probe probeRequest) {
+	item, ok := b.paths[probe.path]
+	switch {
+	case probe.req.Method == "head" && ok && hasOperation(item, "get"):
+		return
+	case probe.req.Method == "options" && ok && len(item.Operations) > 0:
+		if cors := corsMetadata(probe.req); cors != nil {
+			if item.Extensions == nil {
+				item.Extensions = map[string]any{}
+			}
+			item.Extensions["x-cors"] = cors
+			return
+		}
+	}
+
+	reason := "no GET operation on this path"
+	if probe.req.Method == "options" {
+		reason = "no CORS request headers or no sibling operation on this path"
+	}
+	b.warnings = append(b.warnings, Warning{
+		Code:    WarnUnfoldedProbe,
+		File:    probe.req.File,
+		Key:     operationKey(probe.req.Method, probe.path),
+		Message: fmt.Sprintf("cannot fold %s request (%s); emitting it as-is", strings.ToUpper(probe.req.Method), reason),
+	})
+	b.addOperation(probe.path, probe.server, probe.req)
+}
+
+func hasOperation(item *PathItem, method string) bool {
+	_, ok := item.Operations[method]
+	return ok
+}
+
+// corsMetadata extracts the preflight headers of an OPTIONS request, or
+// nil when it does not look like a CORS preflight.
+func corsMetadata(req Request) map[string]any {
+	cors := map[string]any{}
+	for name, value := range req.Headers {
+		if strings.HasPrefix(name, "~") {
+			continue
+		}
+		switch strings.ToLower(name) {
+		case "origin":
+			cors["origin"] = value
+		case "access-control-request-method":
+			cors["methods"] = splitHeaderList(strings.ToUpper(value))
+		case "access-control-request-headers":
+			cors["headers"] = splitHeaderList(value)
+		}
+	}
+	if len(cors) == 0 {
+		return nil
+	}
+	return cors
+}
+
+func splitHeaderList(value string) []string {
+	items := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	sort.Strings(items)
+	return items
+}
@@ -0,0 +1,44 @@
+package bruno2openapi
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFoldProbeMethods(t *testing.T) {
+	requests := []Request{
+		{Method: "get", URL: "/users", File: "users/list.bru"},
+		{Method: "head", URL: "/users", File: "users/head.bru"},
+		{Method: "options", URL: "/users", File: "users/preflight.bru", Headers: map[string]string{
+			"Origin":                         "https://app.example.com",
+			"Access-Control-Request-Method":  "post",
+			"Access-Control-Request-Headers": "X-Tenant-Id, Content-Type",
+		}},
+		{Method: "head", URL: "/orphan", File: "orphan.bru"},
+	}
+
+	doc := buildOpenAPI(requests, Options{FoldProbeMethods: true})
+	users := doc.Paths["/users"]
+	if len(users.Operations) != 1 || !hasOperation(users, "get") {
+		t.Errorf("expected only GET on /users, got %v", users.Operations)
+	}
+	wantCors := map[string]any{
+		"origin":  "https://app.example.com",
+		"methods": []string{"POST"},
+		"headers": []string{"Content-Type", "X-Tenant-Id"},
+	}
+	if !reflect.DeepEqual(users.Extensions["x-cors"], wantCors) {
+		t.Errorf("x-cors = %v, want %v", users.Extensions["x-cors"], wantCors)
+	}
+	if !hasOperation(doc.Paths["/orphan"], "head") {
+		t.Error("unfoldable HEAD should still be emitted")
+	}
+	if len(doc.Warnings) != 1 || doc.Warnings[0].Code != WarnUnfoldedProbe || doc.Warnings[0].File != "orphan.bru" {
+		t.Errorf("unexpected warnings: %v", doc.Warnings)
+	}
+
+	doc = buildOpenAPI(requests, Options{})
+	if len(doc.Paths["/users"].Operations) != 3 {
+		t.Errorf("probes must be emitted as-is by default, got %v", doc.Paths["/users"].Operations)
+	}
+}
@@ -0,0 +1,101 @@
+package bruno2openapi
+
+import (
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// responseHeaderRegex finds response header reads in asserts and tests:
+// res.headers['x-total'], res.headers.etag and res.getHeader("x-total").
+var responseHeaderRegex = regexp.MustCompile(`res\.headers\[\s*['"]([^'"]+)['"]\s*\]|res\.headers\.([A-Za-z_][\w]*)|res\.getHeader\(\s*['"]([^'"]+)['"]\s*\)`)
+
+// responseHeaders documents the response headers a request's asserts and
+// tests rely on, keyed by canonical header name.
+func responseHeaders(req Request) map[string]Header {
+	sources := []string{req.Tests}
+	for expr := range req.Asserts {
+		if !strings.HasPrefix(expr, "~") {
+			sources = append(sources, expr)
+		}
+	}
+	headers := map[string]Header{}
+	for _, src := range sources {
+		for _, m := range responseHeaderRegex.FindAllStringSubmatch(src, -1) {
+			name := m[1] + m[2] + m[3]
+			headers[http.CanonicalHeaderKey(name)] = Header{Schema: &Schema{Type: "string"}}
+		}
+	}
+	if len(headers) == 0 {
+		return nil
+	}
+	return headers
+}
+
+// successResponse documents the success response with the headers and
+// body shape the request's asserts and tests rely on.
+func successResponse(req Request) Response {
+	resp := Response{Description: "Success", Headers: responseHeaders(req)}
+	if schema := assertResponseSchema(req); schema != nil {
+		resp.Content = map[string]MediaType{"application/json": {Schema: schema}}
+	}
+	return resp
+}
+
+// testStatusRegex finds status code checks in a tests script:
+// expect(res.getStatus()).to.equal(201), expect(res.status).to.eql(204),
+// res.getStatus() === 400 and expect(res.status).to.be.oneOf([200, 201]).
+var testStatusRegex = regexp.MustCompile(`(?:res\.getStatus\(\)|res\.status)\s*(?:\)\s*\.to(?:\.be)?\.(?:equal|eql|eq|oneOf)\(\s*\[?([\d,\s]+)\]?\s*\)|===?\s*(\d{3}))`)
+
+// testStatuses returns the distinct status codes a tests script checks
+// for, sorted.
+func testStatuses(tests string) []int {
+	seen := map[int]bool{}
+	codes := []int{}
+	for _, m := range testStatusRegex.FindAllStringSubmatch(tests, -1) {
+		for _, raw := range strings.Split(m[1]+m[2], ",") {
+			if code, ok := parseStatusCode(raw); ok && !seen[code] {
+				seen[code] = true
+				codes = append(codes, code)
+			}
+		}
+	}
+	sort.Ints(codes)
+	return codes
+}
+
+// buildResponses documents the success response plus every other status
+// code the request's tests check for or its saved examples show. Example
+// content takes precedence over the schema derived from asserts.
+func buildResponses(req Request) map[string]Response {
+	primary := successStatus(req)
+	responses := map[string]Response{primary: successResponse(req)}
+	for status, example := range exampleResponses(req) {
+		resp, ok := responses[status]
+		if !ok {
+			responses[status] = example
+			continue
+		}
+		if example.Content != nil {
+			resp.Content = example.Content
+		}
+		for name, header := range example.Headers {
+			if resp.Headers == nil {
+				resp.Headers = map[string]Header{}
+			}
+			if _, ok := resp.Headers[name]; !ok {
+				resp.Headers[name] = header
+			}
+		}
+		responses[status] = resp
+	}
+	for _, code := range testStatuses(req.Tests) {
+		status := strconv.Itoa(code)
+		if _, ok := responses[status]; !ok {
+			responses[status] = Response{Description: http.StatusText(code)}
+		}
+	}
+	return responses
+}
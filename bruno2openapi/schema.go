@@ -0,0 +1,169 @@
+package bruno2openapi
+
+import "math"
+
+// maxSafeInteger is the largest integer a JSON number holds exactly.
+const maxSafeInteger = 1 << 53
+
+// inferJSONSchema derives a schema from a parsed JSON example: objects
+// get their properties, arrays an items schema covering every element
+// (see mergeSchemas), and scalars their type, format and example value.
+// null becomes a nullable schema without a type.
+func inferJSONSchema(value any) *MediaSchema {
+	switch v := value.(type) {
+	case map[string]any:
+		// Required starts as every property of the example; see
+		// applyRequiredProperties.
+		schema := &MediaSchema{Type: "object", Properties: map[string]*MediaSchema{}, Required: sortedKeys(v)}
+		for name, prop := range v {
+			schema.Properties[name] = inferJSONSchema(prop)
+		}
+		return schema
+	case []any:
+		elements := make([]*MediaSchema, len(v))
+		for i, element := range v {
+			elements[i] = inferJSONSchema(element)
+		}
+		return &MediaSchema{Type: "array", Items: mergeSchemas(elements)}
+	case string:
+		typ, format, _ := inferScalar(v)
+		if typ != "string" {
+			format = ""
+		}
+		return &MediaSchema{Type: "string", Format: format, Example: v}
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < maxSafeInteger {
+			return &MediaSchema{Type: "integer", Example: int64(v)}
+		}
+		return &MediaSchema{Type: "number", Example: v}
+	case bool:
+		return &MediaSchema{Type: "boolean", Example: v}
+	case nil:
+		return &MediaSchema{Nullable: true}
+	}
+	return &MediaSchema{}
+}
+
+// mergeSchemas combines the schemas of array elements into one items
+// schema. Elements of the same type merge, objects taking the union of
+// their properties and integers widening to number; null elements make
+// the result nullable. Elements of different types become a oneOf.
+// Schemas that say nothing about the value, such as the items of an
+// empty array, do not constrain the result.
+func mergeSchemas(schemas []*MediaSchema) *MediaSchema {
+	nullable := false
+	variants := []*MediaSchema{}
+	for _, s := range schemas {
+		if s.Type == "" && s.OneOf == nil && s.Ref == "" {
+			nullable = nullable || s.Nullable
+			continue
+		}
+		merged := false
+		for i, v := range variants {
+			if m, ok := mergeSchema(v, s); ok {
+				variants[i] = m
+				merged = true
+				break
+			}
+		}
+		if !merged {
+			variants = append(variants, s)
+		}
+	}
+	var out *MediaSchema
+	switch len(variants) {
+	case 0:
+		out = &MediaSchema{}
+	case 1:
+		out = variants[0]
+	default:
+		out = &MediaSchema{OneOf: variants}
+	}
+	if nullable {
+		out.Nullable = true
+	}
+	return out
+}
+
+// mergeSchema merges two schemas of compatible types; ok is false when
+// they describe different kinds of values.
+func mergeSchema(a, b *MediaSchema) (*MediaSchema, bool) {
+	numeric := func(t string) bool { return t == "integer" || t == "number" }
+	switch {
+	case a.Type == "":
+		return nil, false
+	case a.Type == b.Type:
+	case numeric(a.Type) && numeric(b.Type):
+		out := *a
+		out.Type = "number"
+		return &out, true
+	default:
+		return nil, false
+	}
+	out := *a
+	out.Nullable = a.Nullable || b.Nullable
+	if a.Format != b.Format {
+		out.Format = ""
+	}
+	switch a.Type {
+	case "array":
+		out.Items = mergeSchemas([]*MediaSchema{a.Items, b.Items})
+	case "object":
+		out.Required = intersect(a.Required, b.Required)
+		out.Properties = make(map[string]*MediaSchema, len(a.Properties))
+		for name, prop := range a.Properties {
+			out.Properties[name] = prop
+		}
+		for name, prop := range b.Properties {
+			if existing, ok := out.Properties[name]; ok {
+				out.Properties[name] = mergeSchemas([]*MediaSchema{existing, prop})
+			} else {
+				out.Properties[name] = prop
+			}
+		}
+	}
+	return &out, true
+}
+
+// intersect returns the names in both a and b, in a's order.
+func intersect(a, b []string) []string {
+	if a == nil || b == nil {
+		return nil
+	}
+	out := []string{}
+	for _, name := range a {
+		for _, other := range b {
+			if name == other {
+				out = append(out, name)
+				break
+			}
+		}
+	}
+	return out
+}
+
+// applyRequiredProperties settles the required lists of inferred object
+// schemas, which hold the intersection of the properties of their
+// examples until now. Schemas that were not inferred from JSON have no
+// required list and are left alone.
+func applyRequiredProperties(s *MediaSchema, mode string) {
+	if s == nil {
+		return
+	}
+	if s.Required != nil {
+		switch mode {
+		case RequiredPropertiesAll:
+			s.Required = sortedKeys(s.Properties)
+		case RequiredPropertiesIntersection:
+		default:
+			s.Required = nil
+		}
+	}
+	for _, prop := range s.Properties {
+		applyRequiredProperties(prop, mode)
+	}
+	applyRequiredProperties(s.Items, mode)
+	for _, variant := range s.OneOf {
+		applyRequiredProperties(variant, mode)
+	}
+}
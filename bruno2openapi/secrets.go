@@ -0,0 +1,132 @@
+package bruno2openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const WarnPossibleSecret = "possible-secret"
+
+var (
+	jwtRegex       = regexp.MustCompile(`^eyJ[A-Za-z0-9_-]{5,}\.[A-Za-z0-9_-]{5,}\.[A-Za-z0-9_-]*$`)
+	awsKeyRegex    = regexp.MustCompile(`^(AKIA|ASIA)[0-9A-Z]{16}$`)
+	hexTokenRegex  = regexp.MustCompile(`^[0-9a-fA-F]{32,}$`)
+	b64TokenRegex  = regexp.MustCompile(`^[A-Za-z0-9+/_-]{40,}={0,2}$`)
+	tokenSeparator = regexp.MustCompile(`[\s"'=&,;]+`)
+)
+
+// DetectSecrets scans the headers, query, auth values and body of a parsed
+// request for strings that look like live credentials. Headers and auth
+// inherited from a folder are left to the folder's own entry. Values that reference a {{variable}}
+// are never reported.
+func DetectSecrets(req Request) []Warning {
+	warnings := []Warning{}
+	report := func(location, key, value string) {
+		if kind := secretKind(value); kind != "" {
+			warnings = append(warnings, Warning{
+				Code:    WarnPossibleSecret,
+				File:    req.File,
+				Key:     strings.TrimSpace(location + " " + key),
+				Message: fmt.Sprintf("value looks like a hardcoded %s; use a {{variable}} instead", kind),
+			})
+		}
+	}
+
+	for _, k := range sortedKeys(req.Headers) {
+		if !inheritedHeader(req, k) {
+			report("header", k, req.Headers[k])
+		}
+	}
+	for _, k := range sortedKeys(req.Query) {
+		report("query", k, req.Query[k])
+	}
+	if req.Auth != nil && req.AuthMode != "inherit" {
+		for _, k := range sortedKeys(req.Auth.Values) {
+			report("auth", k, req.Auth.Values[k])
+		}
+	}
+
+	body := strings.TrimSpace(req.Body)
+	if body != "" {
+		var parsed any
+		if err := json.Unmarshal([]byte(body), &parsed); err == nil {
+			walkJSONStrings("", parsed, func(key, value string) {
+				report("body", key, value)
+			})
+		} else {
+			report("body", "", body)
+		}
+	}
+	return warnings
+}
+
+// inheritedHeader reports whether the request's header name was copied
+// from a folder.bru or collection.bru, which is scanned on its own.
+func inheritedHeader(req Request, name string) bool {
+	for _, f := range req.Folders {
+		if value, ok := f.Headers[name]; ok && value == req.Headers[name] {
+			return true
+		}
+	}
+	return false
+}
+
+// secretKind returns a short description of the credential a value
+// resembles, or "" when it looks harmless.
+func secretKind(value string) string {
+	if strings.Contains(value, "{{") {
+		return ""
+	}
+	for _, token := range tokenSeparator.Split(value, -1) {
+		switch {
+		case jwtRegex.MatchString(token):
+			return "JWT"
+		case awsKeyRegex.MatchString(token):
+			return "AWS access key"
+		case hexTokenRegex.MatchString(token):
+			return "hex token"
+		case b64TokenRegex.MatchString(token) && mixedCharClasses(token):
+			return "base64 token"
+		}
+	}
+	return ""
+}
+
+// mixedCharClasses filters out long identifiers such as
+// "this_is_a_very_long_but_harmless_snake_case_name" that happen to fit
+// the base64 alphabet.
+func mixedCharClasses(s string) bool {
+	var upper, lower, digit bool
+	for _, ch := range s {
+		switch {
+		case ch >= 'A' && ch <= 'Z':
+			upper = true
+		case ch >= 'a' && ch <= 'z':
+			lower = true
+		case ch >= '0' && ch <= '9':
+			digit = true
+		}
+	}
+	return upper && lower && digit
+}
+
+func walkJSONStrings(path string, v any, fn func(key, value string)) {
+	switch val := v.(type) {
+	case map[string]any:
+		for _, k := range sortedKeys(val) {
+			if path == "" {
+				walkJSONStrings(k, val[k], fn)
+			} else {
+				walkJSONStrings(path+"."+k, val[k], fn)
+			}
+		}
+	case []any:
+		for i, item := range val {
+			walkJSONStrings(fmt.Sprintf("%s[%d]", path, i), item, fn)
+		}
+	case string:
+		fn(path, val)
+	}
+}
@@ -0,0 +1,168 @@
+package bruno2openapi
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+const WarnSchemeCollision = "security-scheme-collision"
+
+var componentNameUnsafe = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// schemeRegistry names security schemes after their content, so the same
+// definition always gets the same name, identical definitions share one
+// entry and different definitions never overwrite each other.
+type schemeRegistry struct {
+	schemes  map[string]SecurityScheme
+	warnings []Warning
+}
+
+func newSchemeRegistry() *schemeRegistry {
+	return &schemeRegistry{schemes: map[string]SecurityScheme{}}
+}
+
+// collectionAuth returns the auth collection.bru defines for the whole
+// collection.
+func collectionAuth(requests []Request) *Auth {
+	if collection, ok := collectionFolder(requests); ok {
+		return collection.Auth
+	}
+	return nil
+}
+
+// register returns the component name for scheme, adding it on first use.
+// OAuth2 schemes that differ only in their scopes are one scheme whose
+// scopes are merged. A different definition that wants an existing name
+// is stored under a numbered name and reported, with file identifying the
+// source request.
+func (r *schemeRegistry) register(scheme SecurityScheme, file string) string {
+	base := schemeName(scheme)
+	name := base
+	for n := 2; ; n++ {
+		existing, ok := r.schemes[name]
+		if !ok {
+			break
+		}
+		if reflect.DeepEqual(existing, scheme) {
+			return name
+		}
+		if merged, ok := mergeScopes(existing, scheme); ok {
+			r.schemes[name] = merged
+			return name
+		}
+		name = fmt.Sprintf("%s_%d", base, n)
+	}
+	r.schemes[name] = scheme
+	if name != base {
+		r.warnings = append(r.warnings, Warning{
+			Code:    WarnSchemeCollision,
+			File:    file,
+			Key:     "securitySchemes " + base,
+			Message: fmt.Sprintf("a different %s scheme already uses this name; registered as %s", scheme.Type, name),
+		})
+	}
+	return name
+}
+
+// mergeScopes unions the scopes of two oauth2 schemes that are otherwise
+// identical.
+func mergeScopes(a, b SecurityScheme) (SecurityScheme, bool) {
+	if a.Type != "oauth2" || b.Type != "oauth2" || a.Flows == nil || b.Flows == nil {
+		return SecurityScheme{}, false
+	}
+	merged := a
+	flows := *a.Flows
+	merged.Flows = &flows
+	for _, pair := range []struct {
+		dst **OAuthFlow
+		src *OAuthFlow
+	}{
+		{&flows.Implicit, b.Flows.Implicit},
+		{&flows.Password, b.Flows.Password},
+		{&flows.ClientCredentials, b.Flows.ClientCredentials},
+		{&flows.AuthorizationCode, b.Flows.AuthorizationCode},
+	} {
+		if (*pair.dst == nil) != (pair.src == nil) {
+			return SecurityScheme{}, false
+		}
+		if pair.src == nil {
+			continue
+		}
+		flow := **pair.dst
+		other := *pair.src
+		scopes := map[string]string{}
+		for k, v := range flow.Scopes {
+			scopes[k] = v
+		}
+		for k, v := range other.Scopes {
+			scopes[k] = v
+		}
+		flow.Scopes, other.Scopes = nil, nil
+		if !reflect.DeepEqual(flow, other) {
+			return SecurityScheme{}, false
+		}
+		flow.Scopes = scopes
+		*pair.dst = &flow
+	}
+	a.Flows, b.Flows = nil, nil
+	if !reflect.DeepEqual(a, b) {
+		return SecurityScheme{}, false
+	}
+	return merged, true
+}
+
+// schemeName derives a component name from the scheme definition, e.g.
+// bearerAuth, apiKey_X-Api-Key or oauth2_clientCredentials_auth_example_com.
+func schemeName(s SecurityScheme) string {
+	parts := []string{}
+	switch s.Type {
+	case "http":
+		parts = append(parts, strings.ToLower(s.Scheme)+"Auth")
+		if s.BearerFormat != "" {
+			parts = append(parts, s.BearerFormat)
+		}
+	case "apiKey":
+		parts = append(parts, "apiKey")
+		if s.In != "" && s.In != "header" {
+			parts = append(parts, s.In)
+		}
+		parts = append(parts, s.Name)
+	case "oauth2":
+		parts = append(parts, "oauth2")
+		if flow, f := s.Flows.first(); f != nil {
+			parts = append(parts, flow)
+			endpoint := f.TokenURL
+			if endpoint == "" {
+				endpoint = f.AuthorizationURL
+			}
+			if u, err := url.Parse(endpoint); err == nil && u.Host != "" {
+				parts = append(parts, strings.ReplaceAll(u.Hostname(), ".", "_"))
+			}
+		}
+	default:
+		parts = append(parts, s.Type)
+	}
+	return componentNameUnsafe.ReplaceAllString(strings.Join(parts, "_"), "_")
+}
+
+// first returns the name and definition of the first configured flow in
+// a fixed order.
+func (f *OAuthFlows) first() (string, *OAuthFlow) {
+	if f == nil {
+		return "", nil
+	}
+	switch {
+	case f.AuthorizationCode != nil:
+		return "authorizationCode", f.AuthorizationCode
+	case f.ClientCredentials != nil:
+		return "clientCredentials", f.ClientCredentials
+	case f.Password != nil:
+		return "password", f.Password
+	case f.Implicit != nil:
+		return "implicit", f.Implicit
+	}
+	return "", nil
+}
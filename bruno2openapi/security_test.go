@@ -0,0 +1,118 @@
+package bruno2openapi
+
+import "testing"
+
+func TestSchemeRegistryNamesAndMerges(t *testing.T) {
+	r := newSchemeRegistry()
+	apiKey := SecurityScheme{Type: "apiKey", Name: "X-Api-Key", In: "header"}
+	oauth := SecurityScheme{Type: "oauth2", Flows: &OAuthFlows{
+		ClientCredentials: &OAuthFlow{TokenURL: "https://auth.example.com/token", Scopes: map[string]string{}},
+	}}
+
+	if got := r.register(apiKey, "a.bru"); got != "apiKey_X-Api-Key" {
+		t.Errorf("apiKey name = %q", got)
+	}
+	if got := r.register(apiKey, "b.bru"); got != "apiKey_X-Api-Key" {
+		t.Errorf("identical scheme not merged: %q", got)
+	}
+	if got := r.register(oauth, "c.bru"); got != "oauth2_clientCredentials_auth_example_com" {
+		t.Errorf("oauth2 name = %q", got)
+	}
+	if got := r.register(SecurityScheme{Type: "apiKey", Name: "X-Other-Key", In: "header"}, "d.bru"); got != "apiKey_X-Other-Key" {
+		t.Errorf("second apiKey name = %q", got)
+	}
+	if len(r.schemes) != 3 || len(r.warnings) != 0 {
+		t.Errorf("schemes %v, warnings %v", r.schemes, r.warnings)
+	}
+}
+
+func TestSchemeRegistryReportsCollision(t *testing.T) {
+	r := newSchemeRegistry()
+	first := SecurityScheme{Type: "apiKey", Name: "X-Api-Key", In: "header"}
+	second := SecurityScheme{Type: "apiKey", Name: "X-Api-Key", In: "header", Description: "Partner key"}
+
+	r.register(first, "a.bru")
+	if got := r.register(second, "b.bru"); got != "apiKey_X-Api-Key_2" {
+		t.Errorf("colliding scheme name = %q", got)
+	}
+	if got := r.register(second, "c.bru"); got != "apiKey_X-Api-Key_2" {
+		t.Errorf("colliding scheme not reused: %q", got)
+	}
+	if len(r.warnings) != 1 || r.warnings[0].Code != WarnSchemeCollision || r.warnings[0].File != "b.bru" {
+		t.Errorf("unexpected warnings: %v", r.warnings)
+	}
+}
+
+func TestSchemeRegistryMergesOAuthScopes(t *testing.T) {
+	r := newSchemeRegistry()
+	scheme := func(scopes ...string) SecurityScheme {
+		m := map[string]string{}
+		for _, s := range scopes {
+			m[s] = ""
+		}
+		return SecurityScheme{Type: "oauth2", Flows: &OAuthFlows{
+			ClientCredentials: &OAuthFlow{TokenURL: "https://auth.example.com/token", Scopes: m},
+		}}
+	}
+	first := r.register(scheme("read"), "a.bru")
+	second := r.register(scheme("read", "write"), "b.bru")
+	if first != second || len(r.warnings) != 0 {
+		t.Fatalf("scopes not merged: %q %q %v", first, second, r.warnings)
+	}
+	if scopes := r.schemes[first].Flows.ClientCredentials.Scopes; len(scopes) != 2 {
+		t.Errorf("merged scopes = %v", scopes)
+	}
+}
+
+func TestSecuritySchemesDeclaredOnceAndReferenced(t *testing.T) {
+	bearer := &Auth{Mode: "bearer", Values: map[string]string{"token": "{{token}}"}}
+	requests := []Request{
+		{Method: "get", URL: "/users", File: "list.bru", Auth: bearer},
+		{Method: "post", URL: "/users", File: "create.bru", Auth: bearer},
+		{Method: "get", URL: "/health", File: "health.bru"},
+	}
+	doc, err := Build(requests, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.Components == nil || len(doc.Components.SecuritySchemes) != 1 {
+		t.Fatalf("want one security scheme, got %+v", doc.Components)
+	}
+	name := sortedKeys(doc.Components.SecuritySchemes)[0]
+	for _, method := range []string{"get", "post"} {
+		security := doc.Paths["/users"].Operations[method].Security
+		if len(security) != 1 || security[0][name] == nil {
+			t.Errorf("%s /users: security %v does not reference %s", method, security, name)
+		}
+	}
+	if security := doc.Paths["/health"].Operations["get"].Security; security != nil {
+		t.Errorf("unauthenticated request got security %v", security)
+	}
+}
+
+func TestGlobalSecurityOptOut(t *testing.T) {
+	collection := Folder{Path: ".", AuthMode: "bearer", Auth: &Auth{Mode: "bearer", Values: map[string]string{"token": "{{token}}"}}}
+	public := Folder{Path: "public", AuthMode: "none"}
+	requests := []Request{
+		{Method: "get", URL: "/users", File: "users.bru", AuthMode: "inherit", Auth: collection.Auth, Folders: []Folder{collection}},
+		{Method: "get", URL: "/health", File: "health.bru", AuthMode: "none", Folders: []Folder{collection}},
+		{Method: "get", URL: "/docs", File: "public/docs.bru", AuthMode: "inherit", Folders: []Folder{public, collection}},
+		{Method: "get", URL: "/me", File: "me.bru", AuthMode: "bearer", Folders: []Folder{collection}},
+	}
+	doc, err := Build(requests, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Security) != 1 {
+		t.Fatalf("want a global security requirement, got %v", doc.Security)
+	}
+	for path, optOut := range map[string]bool{"/users": false, "/health": true, "/docs": true, "/me": false} {
+		security := doc.Paths[path].Operations["get"].Security
+		if got := security != nil && len(security) == 0; got != optOut {
+			t.Errorf("%s: security %v, want opt-out %v", path, security, optOut)
+		}
+	}
+	if len(doc.Warnings) != 1 || doc.Warnings[0].Code != WarnMissingAuthBlock || doc.Warnings[0].File != "me.bru" {
+		t.Errorf("want a missing-auth-block warning for me.bru, got %v", doc.Warnings)
+	}
+}
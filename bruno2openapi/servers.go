@@ -0,0 +1,106 @@
+package bruno2openapi
+
+import "strings"
+
+// environmentServers documents template, the unresolved server most
+// requests use ({{baseUrl}}), once per environment. Placeholders in the
+// template take the environment's value; placeholders inside that value,
+// or that the environment does not define, become server variables such
+// as {region}, defaulting to the environment's value. Environments that
+// produce the same server share one entry described by all their names.
+// It returns nil when the template has no placeholder to resolve.
+func environmentServers(template string, envs []Environment) []Server {
+	if !placeholderRegex.MatchString(template) {
+		return nil
+	}
+	servers := []Server{}
+	names := [][]string{}
+	for _, env := range envs {
+		server := renderServer(template, env)
+		server.URL = strings.TrimRight(server.URL, "/")
+		found := false
+		for i, s := range servers {
+			if s.URL == server.URL && sameVariables(s.Variables, server.Variables) {
+				names[i] = append(names[i], env.Name)
+				found = true
+				break
+			}
+		}
+		if !found {
+			servers = append(servers, server)
+			names = append(names, []string{env.Name})
+		}
+	}
+	for i := range servers {
+		servers[i].Description = strings.Join(names[i], ", ")
+	}
+	return servers
+}
+
+// renderServer resolves template with the variables of env.
+func renderServer(template string, env Environment) Server {
+	vars := NewVariables()
+	for name, value := range env.Vars {
+		vars.Set(name, value, false)
+	}
+	server := Server{}
+	variable := func(name string) string {
+		if server.Variables == nil {
+			server.Variables = map[string]ServerVariable{}
+		}
+		value := vars.Server("{{" + name + "}}")
+		if placeholderRegex.MatchString(value) {
+			value = ""
+		}
+		server.Variables[name] = ServerVariable{Default: value}
+		return "{" + name + "}"
+	}
+	server.URL = placeholderRegex.ReplaceAllStringFunc(template, func(match string) string {
+		name := placeholderRegex.FindStringSubmatch(match)[1]
+		value, ok := env.Vars[name]
+		if !ok {
+			return variable(name)
+		}
+		return placeholderRegex.ReplaceAllStringFunc(value, func(inner string) string {
+			return variable(placeholderRegex.FindStringSubmatch(inner)[1])
+		})
+	})
+	return server
+}
+
+// urlPath returns the path of an absolute http(s) URL, which may hold
+// {{placeholders}} url.Parse rejects, without its trailing slash; it is
+// empty for other values.
+func urlPath(value string) string {
+	lower := strings.ToLower(value)
+	if !strings.HasPrefix(lower, "http://") && !strings.HasPrefix(lower, "https://") {
+		return ""
+	}
+	rest := value[strings.Index(value, "://")+len("://"):]
+	if i := strings.Index(rest, "/"); i >= 0 {
+		return strings.TrimRight(rest[i:], "/")
+	}
+	return ""
+}
+
+// expandedURL returns the server URL with each variable replaced by its
+// default, for formats without server variables.
+func (s Server) expandedURL() string {
+	url := s.URL
+	for name, v := range s.Variables {
+		url = strings.ReplaceAll(url, "{"+name+"}", v.Default)
+	}
+	return url
+}
+
+func sameVariables(a, b map[string]ServerVariable) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for name, v := range a {
+		if other, ok := b[name]; !ok || other.Default != v.Default {
+			return false
+		}
+	}
+	return true
+}
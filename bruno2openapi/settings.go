@@ -0,0 +1,20 @@
+package bruno2openapi
+
+import "strconv"
+
+// settingsExtension converts a settings block into the x-bruno-settings
+// extension, typing booleans (encodeUrl: true) and integers (timeout: 0)
+// so tooling reading the spec does not have to.
+func settingsExtension(settings map[string]string) map[string]any {
+	out := map[string]any{}
+	for key, value := range settings {
+		if value == "true" || value == "false" {
+			out[key] = value == "true"
+		} else if n, err := strconv.Atoi(value); err == nil {
+			out[key] = n
+		} else {
+			out[key] = value
+		}
+	}
+	return out
+}
@@ -0,0 +1,425 @@
+package bruno2openapi
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// WarnSwaggerLoss is reported for parts of an OpenAPI 3 document that
+// Swagger 2.0 cannot express and that ToSwagger drops or approximates.
+const WarnSwaggerLoss = "swagger-loss"
+
+// Swagger is a Swagger 2.0 (OpenAPI 2) document.
+type Swagger struct {
+	Swagger             string                        `yaml:"swagger"`
+	Info                Info                          `yaml:"info"`
+	Host                string                        `yaml:"host,omitempty"`
+	BasePath            string                        `yaml:"basePath,omitempty"`
+	Schemes             []string                      `yaml:"schemes,omitempty"`
+	Tags                []Tag                         `yaml:"tags,omitempty"`
+	Paths               map[string]*SwaggerPathItem   `yaml:"paths"`
+	Definitions         map[string]*SwaggerSchema     `yaml:"definitions,omitempty"`
+	SecurityDefinitions map[string]SwaggerSecurityDef `yaml:"securityDefinitions,omitempty"`
+	Security            []SecurityRequirement         `yaml:"security,omitempty"`
+	ExternalDocs        *ExternalDocs                 `yaml:"externalDocs,omitempty"`
+	// Extensions holds the document's x- extensions, such as x-tagGroups.
+	Extensions map[string]any `yaml:",inline"`
+
+	// PathOrder, when set, is the order MarshalSwaggerYAML emits paths in
+	// instead of sorting them.
+	PathOrder []string `yaml:"-"`
+	// Warnings lists what the conversion dropped or approximated.
+	Warnings []Warning `yaml:"-"`
+}
+
+// SwaggerPathItem holds the operations of one path keyed by lowercase
+// method, plus path-level x- extensions.
+type SwaggerPathItem struct {
+	Operations map[string]SwaggerOperation
+	Extensions map[string]any
+	Order      []string
+}
+
+func (p SwaggerPathItem) MarshalYAML() (any, error) {
+	operations := map[string]any{}
+	for method, op := range p.Operations {
+		operations[method] = op
+	}
+	return orderedMapping(orderMethods(p.Order, sortedKeys(p.Operations)), operations, p.Extensions)
+}
+
+type SwaggerOperation struct {
+	OperationID  string                     `yaml:"operationId,omitempty"`
+	Summary      string                     `yaml:"summary,omitempty"`
+	Description  string                     `yaml:"description,omitempty"`
+	ExternalDocs *ExternalDocs              `yaml:"externalDocs,omitempty"`
+	Tags         []string                   `yaml:"tags,omitempty"`
+	Consumes     []string                   `yaml:"consumes,omitempty"`
+	Produces     []string                   `yaml:"produces,omitempty"`
+	Parameters   []SwaggerParameter         `yaml:"parameters,omitempty"`
+	Responses    map[string]SwaggerResponse `yaml:"responses"`
+	Deprecated   bool                       `yaml:"deprecated,omitempty"`
+	Security     SecurityRequirements       `yaml:"security,omitempty"`
+	Extensions   map[string]any             `yaml:",inline"`
+}
+
+// SwaggerParameter is a Swagger 2.0 parameter: body parameters carry a
+// schema, all others their type directly.
+type SwaggerParameter struct {
+	Name        string         `yaml:"name"`
+	In          string         `yaml:"in"`
+	Description string         `yaml:"description,omitempty"`
+	Required    bool           `yaml:"required"`
+	Schema      *SwaggerSchema `yaml:"schema,omitempty"`
+	Type        string         `yaml:"type,omitempty"`
+	Format      string         `yaml:"format,omitempty"`
+	Items       *SwaggerSchema `yaml:"items,omitempty"`
+	Enum        []string       `yaml:"enum,omitempty"`
+	Default     any            `yaml:"default,omitempty"`
+	// XExample keeps the OpenAPI 3 example, which 2.0 parameters lack.
+	XExample any `yaml:"x-example,omitempty"`
+}
+
+type SwaggerResponse struct {
+	Description string                   `yaml:"description"`
+	Schema      *SwaggerSchema           `yaml:"schema,omitempty"`
+	Headers     map[string]SwaggerHeader `yaml:"headers,omitempty"`
+	Examples    map[string]any           `yaml:"examples,omitempty"`
+}
+
+type SwaggerHeader struct {
+	Description string `yaml:"description,omitempty"`
+	Type        string `yaml:"type"`
+	Format      string `yaml:"format,omitempty"`
+}
+
+// SwaggerSchema is the Swagger 2.0 subset of MediaSchema: no oneOf, and
+// nullable becomes the x-nullable extension.
+type SwaggerSchema struct {
+	Ref        string                    `yaml:"$ref,omitempty"`
+	Type       string                    `yaml:"type,omitempty"`
+	Format     string                    `yaml:"format,omitempty"`
+	Items      *SwaggerSchema            `yaml:"items,omitempty"`
+	Properties map[string]*SwaggerSchema `yaml:"properties,omitempty"`
+	Required   []string                  `yaml:"required,omitempty"`
+	Enum       []any                     `yaml:"enum,omitempty"`
+	XML        *XMLObject                `yaml:"xml,omitempty"`
+	XNullable  bool                      `yaml:"x-nullable,omitempty"`
+	Example    any                       `yaml:"example,omitempty"`
+}
+
+type SwaggerSecurityDef struct {
+	Type             string        `yaml:"type"`
+	Description      string        `yaml:"description,omitempty"`
+	Name             string        `yaml:"name,omitempty"`
+	In               string        `yaml:"in,omitempty"`
+	Flow             string        `yaml:"flow,omitempty"`
+	AuthorizationURL string        `yaml:"authorizationUrl,omitempty"`
+	TokenURL         string        `yaml:"tokenUrl,omitempty"`
+	Scopes           SwaggerScopes `yaml:"scopes,omitempty"`
+}
+
+// SwaggerScopes lists the scopes of an oauth2 definition. Swagger 2.0
+// requires the key there, so only a nil map is left out and an empty one
+// is written as {}.
+type SwaggerScopes map[string]string
+
+// IsZero reports whether s is left out of the document.
+func (s SwaggerScopes) IsZero() bool {
+	return s == nil
+}
+
+// swaggerFlows maps OpenAPI 3 OAuth flow names to their 2.0 equivalent.
+var swaggerFlows = map[string]string{
+	"authorizationCode": "accessCode",
+	"clientCredentials": "application",
+	"password":          "password",
+	"implicit":          "implicit",
+}
+
+// ToSwagger converts doc to Swagger 2.0. The first server becomes host,
+// basePath and schemes; request bodies become body or formData
+// parameters with the media types in consumes; response media types go
+// to produces. Anything 2.0 cannot express (cookie parameters, trace
+// operations, oneOf, per-operation servers, non-absolute servers) is
+// dropped or approximated with a WarnSwaggerLoss warning.
+func ToSwagger(doc OpenAPI) Swagger {
+	c := &swaggerConverter{}
+	out := Swagger{
+		Swagger:   "2.0",
+		Info:      doc.Info,
+		Tags:      doc.Tags,
+		Paths:     map[string]*SwaggerPathItem{},
+		PathOrder: doc.PathOrder,
+	}
+	if len(doc.Servers) > 0 {
+		out.Host, out.BasePath, out.Schemes = c.server(doc.Servers[0].expandedURL())
+	}
+	out.Security = doc.Security
+	out.Extensions = doc.Extensions
+	out.ExternalDocs = doc.ExternalDocs
+	if doc.Components != nil {
+		for _, name := range sortedKeys(doc.Components.Schemas) {
+			if out.Definitions == nil {
+				out.Definitions = map[string]*SwaggerSchema{}
+			}
+			out.Definitions[name] = c.schema(doc.Components.Schemas[name], "", name)
+		}
+		for _, name := range sortedKeys(doc.Components.SecuritySchemes) {
+			if def, ok := c.securityDef(name, doc.Components.SecuritySchemes[name]); ok {
+				if out.SecurityDefinitions == nil {
+					out.SecurityDefinitions = map[string]SwaggerSecurityDef{}
+				}
+				out.SecurityDefinitions[name] = def
+			}
+		}
+	}
+	for _, pathName := range sortedKeys(doc.Paths) {
+		item := doc.Paths[pathName]
+		swaggerItem := &SwaggerPathItem{Operations: map[string]SwaggerOperation{}, Extensions: item.Extensions, Order: item.Order}
+		for _, method := range item.methods() {
+			key := operationKey(method, pathName)
+			if method == "trace" {
+				c.warn(doc.Sources[pathName][method], key, "Swagger 2.0 has no trace operations; operation left out")
+				continue
+			}
+			swaggerItem.Operations[method] = c.operation(item.Operations[method], doc.Sources[pathName][method], key)
+		}
+		if len(swaggerItem.Order) > 0 {
+			order := []string{}
+			for _, method := range swaggerItem.Order {
+				if _, ok := swaggerItem.Operations[method]; ok {
+					order = append(order, method)
+				}
+			}
+			swaggerItem.Order = order
+		}
+		out.Paths[pathName] = swaggerItem
+	}
+	out.Warnings = c.warnings
+	return out
+}
+
+type swaggerConverter struct {
+	warnings []Warning
+}
+
+func (c *swaggerConverter) warn(file, key, message string) {
+	c.warnings = append(c.warnings, Warning{Code: WarnSwaggerLoss, File: file, Key: key, Message: message})
+}
+
+// server splits an absolute server URL into host, basePath and schemes.
+func (c *swaggerConverter) server(raw string) (host, basePath string, schemes []string) {
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		c.warn("", "", fmt.Sprintf("server %q is not an absolute URL; host and basePath left out", raw))
+		return "", "", nil
+	}
+	basePath = strings.TrimRight(u.Path, "/")
+	if basePath == "" {
+		basePath = "/"
+	}
+	return u.Host, basePath, []string{u.Scheme}
+}
+
+func (c *swaggerConverter) operation(op Operation, file, key string) SwaggerOperation {
+	out := SwaggerOperation{
+		OperationID:  op.OperationID,
+		Summary:      op.Summary,
+		Description:  op.Description,
+		Tags:         op.Tags,
+		ExternalDocs: op.ExternalDocs,
+		Responses:    map[string]SwaggerResponse{},
+		Deprecated:   op.Deprecated,
+		Security:     op.Security,
+		Extensions:   op.Extensions,
+	}
+	if len(op.Servers) > 0 {
+		c.warn(file, key, fmt.Sprintf("Swagger 2.0 has no per-operation servers; %s left out", op.Servers[0].URL))
+	}
+	for _, p := range op.Parameters {
+		if p.In == "cookie" {
+			c.warn(file, key, fmt.Sprintf("Swagger 2.0 has no cookie parameters; %s left out", p.Name))
+			continue
+		}
+		out.Parameters = append(out.Parameters, SwaggerParameter{
+			Name:        p.Name,
+			In:          p.In,
+			Description: p.Description,
+			Required:    p.Required,
+			Type:        firstNonEmpty(p.Schema.Type, "string"),
+			Format:      p.Schema.Format,
+			Enum:        p.Schema.Enum,
+			Default:     p.Schema.Default,
+			XExample:    p.Example,
+		})
+	}
+	if op.RequestBody != nil {
+		out.Consumes = sortedKeys(op.RequestBody.Content)
+		out.Parameters = append(out.Parameters, c.bodyParameters(op.RequestBody, file, key)...)
+	}
+	produces := map[string]bool{}
+	for code, resp := range op.Responses {
+		swaggerResp := SwaggerResponse{Description: resp.Description}
+		for _, name := range sortedKeys(resp.Headers) {
+			if swaggerResp.Headers == nil {
+				swaggerResp.Headers = map[string]SwaggerHeader{}
+			}
+			h := resp.Headers[name]
+			header := SwaggerHeader{Description: h.Description, Type: "string"}
+			if h.Schema != nil {
+				header.Type = firstNonEmpty(h.Schema.Type, "string")
+				header.Format = h.Schema.Format
+			}
+			swaggerResp.Headers[name] = header
+		}
+		for i, contentType := range sortedKeys(resp.Content) {
+			produces[contentType] = true
+			media := resp.Content[contentType]
+			if i == 0 {
+				swaggerResp.Schema = c.schema(media.Schema, file, key)
+			}
+			if example := mediaExample(media); example != nil {
+				if swaggerResp.Examples == nil {
+					swaggerResp.Examples = map[string]any{}
+				}
+				swaggerResp.Examples[contentType] = example
+			}
+		}
+		out.Responses[code] = swaggerResp
+	}
+	if len(produces) > 0 {
+		out.Produces = sortedKeys(produces)
+	}
+	return out
+}
+
+// bodyParameters turns a request body into formData parameters for form
+// and multipart bodies, and into a single body parameter otherwise. Only
+// the first media type's schema survives; the rest are listed in consumes.
+func (c *swaggerConverter) bodyParameters(rb *RequestBody, file, key string) []SwaggerParameter {
+	contentTypes := sortedKeys(rb.Content)
+	media := rb.Content[contentTypes[0]]
+	if len(contentTypes) > 1 {
+		c.warn(file, key, fmt.Sprintf("Swagger 2.0 has one body schema per operation; using the %s schema", contentTypes[0]))
+	}
+	base := strings.ToLower(contentTypes[0])
+	if (strings.HasPrefix(base, "application/x-www-form-urlencoded") || strings.HasPrefix(base, "multipart/form-data")) &&
+		media.Schema != nil && media.Schema.Type == "object" {
+		params := []SwaggerParameter{}
+		for _, name := range sortedKeys(media.Schema.Properties) {
+			prop := media.Schema.Properties[name]
+			param := SwaggerParameter{Name: name, In: "formData", Type: firstNonEmpty(prop.Type, "string"), Format: prop.Format}
+			if prop.Type == "string" && prop.Format == "binary" {
+				param.Type, param.Format = "file", ""
+			}
+			if prop.Type == "array" {
+				param.Items = c.schema(prop.Items, file, key)
+				if param.Items != nil && param.Items.Format == "binary" {
+					c.warn(file, key, fmt.Sprintf("Swagger 2.0 cannot upload several files in one field; %s documented as a single file", name))
+					param.Type, param.Format, param.Items = "file", "", nil
+				}
+			}
+			for _, req := range media.Schema.Required {
+				if req == name {
+					param.Required = true
+				}
+			}
+			params = append(params, param)
+		}
+		return params
+	}
+	schema := c.schema(media.Schema, file, key)
+	if schema != nil {
+		if example := mediaExample(media); example != nil && schema.Example == nil && schema.Ref == "" {
+			schema.Example = example
+		}
+	}
+	return []SwaggerParameter{{Name: "body", In: "body", Required: rb.Required, Schema: schema}}
+}
+
+// mediaExample returns the media type's example, or its first named
+// example by name.
+func mediaExample(media MediaType) any {
+	if media.Example != nil {
+		return media.Example
+	}
+	if names := sortedKeys(media.Examples); len(names) > 0 {
+		return media.Examples[names[0]].Value
+	}
+	return nil
+}
+
+// schema converts s, rewriting component references to definitions and
+// replacing oneOf, which 2.0 lacks, with an untyped schema.
+func (c *swaggerConverter) schema(s *MediaSchema, file, key string) *SwaggerSchema {
+	if s == nil {
+		return nil
+	}
+	out := &SwaggerSchema{
+		Ref:       strings.Replace(s.Ref, "#/components/schemas/", "#/definitions/", 1),
+		Type:      s.Type,
+		Format:    s.Format,
+		Required:  s.Required,
+		Enum:      s.Enum,
+		XML:       s.XML,
+		XNullable: s.Nullable,
+		Example:   s.Example,
+	}
+	if len(s.OneOf) > 0 {
+		c.warn(file, key, "Swagger 2.0 has no oneOf; mixed values documented without a type")
+	}
+	out.Items = c.schema(s.Items, file, key)
+	if s.Properties != nil {
+		out.Properties = make(map[string]*SwaggerSchema, len(s.Properties))
+		for name, prop := range s.Properties {
+			out.Properties[name] = c.schema(prop, file, key)
+		}
+	}
+	return out
+}
+
+// securityDef converts a security scheme. Bearer tokens have no 2.0
+// scheme and become an Authorization apiKey header; OAuth2 keeps its
+// first flow.
+func (c *swaggerConverter) securityDef(name string, s SecurityScheme) (SwaggerSecurityDef, bool) {
+	switch s.Type {
+	case "http":
+		if strings.EqualFold(s.Scheme, "basic") {
+			return SwaggerSecurityDef{Type: "basic", Description: s.Description}, true
+		}
+		c.warn("", name, fmt.Sprintf("Swagger 2.0 has no %s scheme; documented as an Authorization header", s.Scheme))
+		return SwaggerSecurityDef{Type: "apiKey", Name: "Authorization", In: "header", Description: firstNonEmpty(s.Description, "Bearer token, sent as \"Bearer <token>\".")}, true
+	case "apiKey":
+		if s.In == "cookie" {
+			c.warn("", name, "Swagger 2.0 has no cookie API keys; scheme left out")
+			return SwaggerSecurityDef{}, false
+		}
+		return SwaggerSecurityDef{Type: "apiKey", Name: s.Name, In: s.In, Description: s.Description}, true
+	case "oauth2":
+		flowName, flow := s.Flows.first()
+		if flow == nil {
+			return SwaggerSecurityDef{}, false
+		}
+		scopes := SwaggerScopes{}
+		for scope, desc := range flow.Scopes {
+			scopes[scope] = desc
+		}
+		return SwaggerSecurityDef{
+			Type:             "oauth2",
+			Description:      s.Description,
+			Flow:             swaggerFlows[flowName],
+			AuthorizationURL: flow.AuthorizationURL,
+			TokenURL:         flow.TokenURL,
+			Scopes:           scopes,
+		}, true
+	}
+	c.warn("", name, fmt.Sprintf("Swagger 2.0 has no %s security scheme; scheme left out", s.Type))
+	return SwaggerSecurityDef{}, false
+}
+
+// MarshalSwaggerYAML encodes a Swagger 2.0 document like MarshalYAML.
+func MarshalSwaggerYAML(doc Swagger) ([]byte, error) {
+	return marshalDocument(doc, doc.PathOrder)
+}
@@ -0,0 +1,99 @@
+package bruno2openapi
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestToSwagger(t *testing.T) {
+	requests := []Request{
+		{Method: "post", URL: "{{baseUrl}}/users", Name: "Create User", File: "create.bru", BodyType: "json", Body: `{"name": "Ana"}`,
+			Auth: &Auth{Mode: "bearer", Values: map[string]string{"token": "t"}}},
+		{Method: "put", URL: "{{baseUrl}}/users/:id", Name: "Replace User", File: "replace.bru", BodyType: "json", Body: `{"name": "Budi"}`,
+			Headers: map[string]string{"Cookie": "session=abc"}},
+		{Method: "post", URL: "{{baseUrl}}/login", Name: "Login", File: "login.bru", BodyType: "form-urlencoded", Body: "user: ana\npass: x"},
+		{Method: "trace", URL: "{{baseUrl}}/debug", File: "trace.bru"},
+	}
+	vars := NewVariables()
+	vars.Set("baseUrl", "https://api.example.com/v1", false)
+	doc, err := Build(requests, Options{Variables: vars})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sw := ToSwagger(doc)
+
+	if sw.Swagger != "2.0" || sw.Host != "api.example.com" || sw.BasePath != "/v1" || strings.Join(sw.Schemes, ",") != "https" {
+		t.Errorf("server not split: host %q basePath %q schemes %v", sw.Host, sw.BasePath, sw.Schemes)
+	}
+	if def := sw.Definitions["CreateUserBody"]; def == nil || def.Properties["name"] == nil {
+		t.Errorf("shared schema not moved to definitions: %v", sw.Definitions)
+	}
+
+	create := sw.Paths["/users"].Operations["post"]
+	body := create.Parameters[0]
+	if body.In != "body" || body.Schema.Ref != "#/definitions/CreateUserBody" || strings.Join(create.Consumes, ",") != "application/json" {
+		t.Errorf("unexpected body parameter %+v, consumes %v", body, create.Consumes)
+	}
+	if def := sw.SecurityDefinitions["bearerAuth"]; def.Type != "apiKey" || def.In != "header" || def.Name != "Authorization" {
+		t.Errorf("bearer scheme = %+v", def)
+	}
+
+	login := sw.Paths["/login"].Operations["post"]
+	names := []string{}
+	for _, p := range login.Parameters {
+		if p.In != "formData" {
+			t.Errorf("form field %s in %s", p.Name, p.In)
+		}
+		names = append(names, p.Name)
+	}
+	if strings.Join(names, ",") != "pass,user" {
+		t.Errorf("form fields = %v", names)
+	}
+
+	replace := sw.Paths["/users/{id}"].Operations["put"]
+	if replace.Parameters[0].In != "path" || replace.Parameters[0].Type != "string" || replace.Parameters[0].Schema != nil {
+		t.Errorf("path parameter = %+v", replace.Parameters[0])
+	}
+	if _, ok := sw.Paths["/debug"].Operations["trace"]; ok {
+		t.Error("trace operation kept")
+	}
+	losses := map[string]bool{}
+	for _, w := range sw.Warnings {
+		if w.Code == WarnSwaggerLoss {
+			losses[w.File] = true
+		}
+	}
+	if !losses["replace.bru"] || !losses["trace.bru"] {
+		t.Errorf("want losses for the cookie and the trace request, got %v", sw.Warnings)
+	}
+
+	out, err := MarshalSwaggerYAML(sw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(out), "swagger: \"2.0\"\n") || strings.Contains(string(out), "requestBody") {
+		t.Errorf("unexpected YAML:\n%s", out)
+	}
+}
+
+func TestSwaggerOAuth2WithoutScopes(t *testing.T) {
+	requests := []Request{
+		{Method: "get", URL: "/users", File: "list.bru",
+			Auth: &Auth{Mode: "oauth2", Values: map[string]string{"grant_type": "client_credentials", "access_token_url": "https://auth.example.com/token"}}},
+	}
+	doc, err := Build(requests, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sw := ToSwagger(doc)
+	out, err := MarshalSwaggerYAML(sw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(out), "type: oauth2") || !strings.Contains(string(out), "scopes: {}") {
+		t.Errorf("oauth2 definition without scopes:\n%s", out)
+	}
+	if strings.Count(string(out), "scopes:") != 1 {
+		t.Errorf("scopes written outside the oauth2 definition:\n%s", out)
+	}
+}
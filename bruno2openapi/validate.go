@@ -0,0 +1,91 @@
+package bruno2openapi
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Warning codes reported by Validate.
+const (
+	WarnEmptyPaths         = "empty-paths"
+	WarnDuplicateParameter = "duplicate-parameter"
+	WarnPathParamMismatch  = "path-param-mismatch"
+	WarnUnresolvedVariable = "unresolved-variable"
+	WarnBodyNotAllowed     = "body-not-allowed"
+)
+
+// Validate checks a built document for problems that OpenAPI tooling
+// rejects or that indicate a conversion gap. Each finding names the .bru
+// file that produced the offending operation.
+func Validate(doc OpenAPI) []Warning {
+	warnings := []Warning{}
+	if len(doc.Paths) == 0 {
+		warnings = append(warnings, Warning{
+			Code:    WarnEmptyPaths,
+			Message: "the document has no paths",
+		})
+	}
+
+	for _, pathName := range sortedKeys(doc.Paths) {
+		if strings.Contains(pathName, "{{") {
+			for _, method := range sortedKeys(doc.Paths[pathName].Operations) {
+				warnings = append(warnings, Warning{
+					Code:    WarnUnresolvedVariable,
+					File:    doc.Sources[pathName][method],
+					Key:     operationKey(method, pathName),
+					Message: "path still contains an unresolved {{variable}}",
+				})
+			}
+		}
+
+		templateParams := map[string]bool{}
+		for _, name := range extractPathParams(pathName) {
+			templateParams[name] = true
+		}
+
+		for _, method := range sortedKeys(doc.Paths[pathName].Operations) {
+			op := doc.Paths[pathName].Operations[method]
+			report := func(code, format string, args ...any) {
+				warnings = append(warnings, Warning{
+					Code:    code,
+					File:    doc.Sources[pathName][method],
+					Key:     operationKey(method, pathName),
+					Message: fmt.Sprintf(format, args...),
+				})
+			}
+
+			seen := map[string]bool{}
+			declared := map[string]bool{}
+			for _, p := range op.Parameters {
+				key := p.In + ":" + p.Name
+				if p.In == "header" {
+					key = strings.ToLower(key)
+				}
+				if seen[key] {
+					report(WarnDuplicateParameter, "%s parameter %q is declared more than once", p.In, p.Name)
+				}
+				seen[key] = true
+				if p.In == "path" {
+					declared[p.Name] = true
+					if !templateParams[p.Name] {
+						report(WarnPathParamMismatch, "path parameter %q does not appear in the path template", p.Name)
+					}
+				}
+			}
+			for _, name := range extractPathParams(pathName) {
+				if !declared[name] {
+					report(WarnPathParamMismatch, "path placeholder {%s} has no matching path parameter", name)
+				}
+			}
+
+			if op.RequestBody != nil && !bodyAllowed(method) {
+				report(WarnBodyNotAllowed, "%s operations should not have a request body", strings.ToUpper(method))
+			}
+		}
+	}
+	return warnings
+}
+
+func operationKey(method, pathName string) string {
+	return strings.ToUpper(method) + " " + pathName
+}
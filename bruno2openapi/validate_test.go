@@ -0,0 +1,47 @@
+package bruno2openapi
+
+import "testing"
+
+func TestValidate(t *testing.T) {
+	requests := []Request{
+		{Method: "get", URL: "{{baseUrl}}/users/:id", File: "users/get.bru", PathParams: map[string]string{"userId": "1"}},
+		{Method: "get", URL: "{{baseUrl}}/search", File: "search.bru", Body: `{"q": "x"}`},
+		{Method: "post", URL: "{{baseUrl}}/api/{{version}}/orders", File: "orders/create.bru"},
+		{Method: "get", URL: "/dupes", File: "dupes.bru", Headers: map[string]string{"X-Trace": "a", "x-trace": "b"}},
+	}
+	doc := buildOpenAPI(requests, Options{})
+	// Build merges headers differing only by case, so declare the
+	// duplicate by hand.
+	op := doc.Paths["/dupes"].Operations["get"]
+	op.Parameters = append(op.Parameters, Parameter{Name: "x-trace", In: "header"})
+	doc.Paths["/dupes"].Operations["get"] = op
+	// Build turns {{version}} into a {version} path parameter; Validate
+	// still catches placeholders in documents assembled by hand.
+	doc.Paths["/api/{{version}}/orders"] = doc.Paths["/api/{version}/orders"]
+	doc.Sources["/api/{{version}}/orders"] = doc.Sources["/api/{version}/orders"]
+	delete(doc.Paths, "/api/{version}/orders")
+	warnings := Validate(doc)
+
+	got := map[string]string{}
+	for _, w := range warnings {
+		got[w.Code] = w.File
+	}
+	want := map[string]string{
+		WarnPathParamMismatch:  "users/get.bru",
+		WarnBodyNotAllowed:     "search.bru",
+		WarnUnresolvedVariable: "orders/create.bru",
+		WarnDuplicateParameter: "dupes.bru",
+	}
+	for code, file := range want {
+		if got[code] != file {
+			t.Errorf("%s: got file %q, want %q (all: %v)", code, got[code], file, warnings)
+		}
+	}
+}
+
+func TestValidateEmptyPaths(t *testing.T) {
+	warnings := Validate(buildOpenAPI(nil, Options{}))
+	if len(warnings) != 1 || warnings[0].Code != WarnEmptyPaths {
+		t.Errorf("unexpected warnings: %v", warnings)
+	}
+}
@@ -0,0 +1,289 @@
+package bruno2openapi
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// RedactedValue replaces secret variable values in generated examples.
+const RedactedValue = "<redacted>"
+
+// WarnEnvironmentConflict is reported when a parameter is set to a
+// variable whose value differs between environments and none is selected.
+const WarnEnvironmentConflict = "environment-conflict"
+
+var placeholderRegex = regexp.MustCompile(`\{\{\s*([^{}\s]+)\s*\}\}`)
+
+// Variables resolves Bruno {{name}} placeholders. Every value remembers
+// whether it is secret, so that secrets can be used to resolve server URLs
+// without ever being copied into examples or path templates.
+type Variables struct {
+	values map[string]variable
+	secret map[string]bool
+	// envValues records, per variable, the value each environment gives
+	// it, so values that differ between environments are not flattened
+	// into whichever environment was added last.
+	envValues map[string]map[string]string
+	selected  string
+}
+
+type variable struct {
+	value  string
+	secret bool
+}
+
+func NewVariables() *Variables {
+	return &Variables{values: map[string]variable{}, secret: map[string]bool{}, envValues: map[string]map[string]string{}}
+}
+
+// Set defines name. Values whose name an environment lists under
+// vars:secret should be set with secret true.
+func (v *Variables) Set(name, value string, secret bool) {
+	v.values[name] = variable{value: value, secret: secret || v.secret[name]}
+}
+
+// MarkSecret flags name as secret, including a value set later.
+func (v *Variables) MarkSecret(name string) {
+	v.secret[name] = true
+	if val, ok := v.values[name]; ok {
+		val.secret = true
+		v.values[name] = val
+	}
+}
+
+// AddEnvironment defines the variables of env, honoring its secret list.
+// When several environments define the same variable, the selected
+// environment's value wins, otherwise the one added last.
+func (v *Variables) AddEnvironment(env Environment) {
+	for name := range env.Secret {
+		v.MarkSecret(name)
+	}
+	for name, value := range env.Vars {
+		if v.envValues[name] == nil {
+			v.envValues[name] = map[string]string{}
+		}
+		v.envValues[name][env.Name] = value
+		if _, ok := v.envValues[name][v.selected]; ok && env.Name != v.selected {
+			continue
+		}
+		v.Set(name, value, env.Secret[name])
+	}
+}
+
+// SelectEnvironment marks name (the -e environment) as the one whose
+// values are used when environments disagree. Call it before adding
+// environments.
+func (v *Variables) SelectEnvironment(name string) {
+	v.selected = name
+}
+
+// EnvironmentValues reports the values environments give name, keyed by
+// environment, when at least two of them differ. The second result is
+// the selected environment's value, empty when none was selected or it
+// does not define name.
+func (v *Variables) EnvironmentValues(name string) (map[string]string, string) {
+	if v == nil {
+		return nil, ""
+	}
+	envs := v.envValues[name]
+	distinct := map[string]bool{}
+	for _, value := range envs {
+		distinct[value] = true
+	}
+	if len(distinct) < 2 {
+		return nil, ""
+	}
+	return envs, envs[v.selected]
+}
+
+// With returns a copy of v in which values override the variables of the
+// same name, e.g. a request's vars:pre-request block on top of the
+// collection and environment. v may be nil.
+func (v *Variables) With(values map[string]string) *Variables {
+	if len(values) == 0 {
+		return v
+	}
+	scoped := NewVariables()
+	if v != nil {
+		for name, val := range v.values {
+			scoped.values[name] = val
+		}
+		for name := range v.secret {
+			scoped.secret[name] = true
+		}
+		for name, envs := range v.envValues {
+			scoped.envValues[name] = envs
+		}
+		scoped.selected = v.selected
+	}
+	for name, value := range values {
+		scoped.Set(name, value, false)
+		// A value set on the request no longer depends on the environment.
+		delete(scoped.envValues, name)
+	}
+	return scoped
+}
+
+// Has reports whether name is defined.
+func (v *Variables) Has(name string) bool {
+	if v == nil {
+		return false
+	}
+	_, ok := v.values[name]
+	return ok
+}
+
+// IsSecret reports whether name is a secret variable.
+func (v *Variables) IsSecret(name string) bool {
+	if v == nil {
+		return false
+	}
+	return v.secret[name] || v.values[name].secret
+}
+
+// Example resolves placeholders for use in an example value; secret
+// variables become RedactedValue.
+func (v *Variables) Example(s string) string {
+	return v.replace(s, func(_ string, val variable) string {
+		if val.secret {
+			return RedactedValue
+		}
+		return val.value
+	})
+}
+
+// Path resolves placeholders in a path template. Secret variables keep
+// their {{name}} placeholder so the value never appears in the spec.
+func (v *Variables) Path(s string) string {
+	return v.replace(s, func(placeholder string, val variable) string {
+		if val.secret {
+			return placeholder
+		}
+		return val.value
+	})
+}
+
+// Server resolves every placeholder, secrets included, for server URLs.
+func (v *Variables) Server(s string) string {
+	return v.replace(s, func(_ string, val variable) string { return val.value })
+}
+
+// maxVariableDepth bounds nested resolution ({{baseUrl}} -> {{host}}) and
+// stops self-referencing variables from looping.
+const maxVariableDepth = 8
+
+// replace substitutes known variables using render, which receives the
+// original placeholder text. Values that themselves contain placeholders
+// are resolved again; unknown variables are left untouched.
+func (v *Variables) replace(s string, render func(placeholder string, val variable) string) string {
+	if v == nil {
+		return s
+	}
+	for depth := 0; depth < maxVariableDepth && strings.Contains(s, "{{"); depth++ {
+		next := placeholderRegex.ReplaceAllStringFunc(s, func(match string) string {
+			name := placeholderRegex.FindStringSubmatch(match)[1]
+			if val, ok := v.values[name]; ok {
+				return render(match, val)
+			}
+			return match
+		})
+		if next == s {
+			break
+		}
+		s = next
+	}
+	return s
+}
+
+// Environment is a parsed Bruno environment file (environments/*.bru).
+type Environment struct {
+	Name   string
+	Vars   map[string]string
+	Secret map[string]bool
+}
+
+// ParseEnvironment reads the vars block and the vars:secret name list of a
+// Bruno environment file. Secret values are not stored in the file; they
+// come from elsewhere, for example a --var flag.
+func ParseEnvironment(r io.Reader) (Environment, error) {
+	env := Environment{Vars: map[string]string{}, Secret: map[string]bool{}}
+	section := ""
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return env, err
+	}
+	scanner := bufio.NewScanner(strings.NewReader(decodeText(content)))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "":
+		case line == "vars {":
+			section = "vars"
+		case line == "vars:secret [":
+			section = "secret"
+		case line == "}" || line == "]":
+			section = ""
+		case section == "vars":
+			if k, val := splitKeyValue(line); k != "" {
+				env.Vars[k] = val
+			}
+		case section == "secret":
+			for _, name := range strings.Split(line, ",") {
+				if name = strings.TrimSpace(name); name != "" {
+					env.Secret[name] = true
+				}
+			}
+		}
+	}
+	return env, scanner.Err()
+}
+
+// environmentsDir is the collection folder holding environment files.
+const environmentsDir = "environments"
+
+// LoadEnvironments parses every environments/*.bru file of the collection
+// at root, named after their file, sorted by name.
+func LoadEnvironments(fsys fs.FS, root string) ([]Environment, error) {
+	files, err := fs.Glob(fsys, path.Join(root, environmentsDir, "*.bru"))
+	if err != nil {
+		return nil, err
+	}
+	envs := []Environment{}
+	for _, file := range files {
+		f, err := fsys.Open(file)
+		if err != nil {
+			return nil, fmt.Errorf("reading environment %s: %w", file, err)
+		}
+		env, err := ParseEnvironment(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("parsing environment %s: %w", file, err)
+		}
+		env.Name = strings.TrimSuffix(path.Base(file), ".bru")
+		envs = append(envs, env)
+	}
+	sort.Slice(envs, func(i, j int) bool { return envs[i].Name < envs[j].Name })
+	return envs, nil
+}
+
+// FindEnvironment returns the environment called name, or an error listing
+// the available ones.
+func FindEnvironment(envs []Environment, name string) (Environment, error) {
+	names := []string{}
+	for _, env := range envs {
+		if env.Name == name {
+			return env, nil
+		}
+		names = append(names, env.Name)
+	}
+	if len(names) == 0 {
+		return Environment{}, fmt.Errorf("environment %q not found: the collection has no %s folder", name, environmentsDir)
+	}
+	return Environment{}, fmt.Errorf("environment %q not found (available: %s)", name, strings.Join(names, ", "))
+}
@@ -0,0 +1,187 @@
+package bruno2openapi
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestParseEnvironment(t *testing.T) {
+	env, err := ParseEnvironment(strings.NewReader(`vars {
+  baseUrl: https://api.example.com
+  tenant: acme
+}
+vars:secret [
+  adminToken,
+  apiKey
+]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if env.Vars["baseUrl"] != "https://api.example.com" || env.Vars["tenant"] != "acme" {
+		t.Errorf("unexpected vars: %v", env.Vars)
+	}
+	if !env.Secret["adminToken"] || !env.Secret["apiKey"] || env.Secret["tenant"] {
+		t.Errorf("unexpected secrets: %v", env.Secret)
+	}
+}
+
+func TestSecretVariablesNeverLeakIntoExamples(t *testing.T) {
+	vars := NewVariables()
+	vars.AddEnvironment(Environment{
+		Vars:   map[string]string{"baseUrl": "https://{{host}}", "tenant": "acme"},
+		Secret: map[string]bool{"adminToken": true},
+	})
+	vars.Set("adminToken", "s3cr3t-admin", false) // value from an env file, secret via the environment
+	vars.Set("host", "internal.example.com", true)
+
+	req := Request{
+		Method:     "post",
+		URL:        "{{baseUrl}}/admin/{{adminToken}}/tenants/{{tenant}}",
+		Query:      map[string]string{"token": "{{adminToken}}", "tenant": "{{tenant}}"},
+		PathParams: map[string]string{},
+		Headers:    map[string]string{"X-Admin-Token": "{{adminToken}}"},
+		Body:       `{"token": "{{adminToken}}", "tenant": "{{tenant}}"}`,
+		BodyType:   "json",
+	}
+	doc := buildOpenAPI([]Request{req}, Options{Variables: vars})
+	out, err := MarshalYAML(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	spec := string(out)
+
+	if strings.Contains(spec, "s3cr3t-admin") {
+		t.Fatalf("secret value leaked into the spec:\n%s", spec)
+	}
+	if _, ok := doc.Paths["/admin/{adminToken}/tenants/acme"]; !ok {
+		t.Errorf("secret path segment should become a path template parameter, got paths %v", sortedKeys(doc.Paths))
+	}
+	if len(doc.Servers) != 1 || doc.Servers[0].URL != "https://internal.example.com" {
+		t.Errorf("secrets should still resolve servers, got %v", doc.Servers)
+	}
+	op := doc.Paths["/admin/{adminToken}/tenants/acme"].Operations["post"]
+	wantExamples := map[string]string{"token": RedactedValue, "tenant": "acme", "X-Admin-Token": RedactedValue}
+	for _, p := range op.Parameters {
+		want, ok := wantExamples[p.Name]
+		if ok && p.Example != want {
+			t.Errorf("%s %s example = %v, want %q", p.In, p.Name, p.Example, want)
+		}
+	}
+	body := op.RequestBody.Content["application/json"].Example.(map[string]any)
+	if body["token"] != RedactedValue || body["tenant"] != "acme" {
+		t.Errorf("unexpected body example: %v", body)
+	}
+}
+
+func TestConflictingEnvironmentValuesBecomeEnum(t *testing.T) {
+	requests := []Request{{
+		Method:  "get",
+		URL:     "/users",
+		Name:    "List Users",
+		File:    "users.bru",
+		Headers: map[string]string{"X-Api-Version": "{{apiVersion}}", "X-Client": "{{client}}"},
+	}}
+	build := func(selected string) OpenAPI {
+		vars := NewVariables()
+		vars.SelectEnvironment(selected)
+		vars.AddEnvironment(Environment{Name: "local", Vars: map[string]string{"apiVersion": "v2", "client": "cli"}})
+		vars.AddEnvironment(Environment{Name: "prod", Vars: map[string]string{"apiVersion": "v1", "client": "cli"}})
+		doc, err := Build(requests, Options{Variables: vars})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return doc
+	}
+	param := func(doc OpenAPI, name string) Parameter {
+		for _, p := range doc.Paths["/users"].Operations["get"].Parameters {
+			if p.Name == name {
+				return p
+			}
+		}
+		t.Fatalf("parameter %s missing", name)
+		return Parameter{}
+	}
+
+	doc := build("local")
+	version := param(doc, "X-Api-Version")
+	if !reflect.DeepEqual(version.Schema.Enum, []string{"v1", "v2"}) || version.Schema.Default != "v2" || version.Example != "v2" {
+		t.Errorf("selected environment not used as default: %+v", version)
+	}
+	if !strings.Contains(version.Description, "local=v2, prod=v1") {
+		t.Errorf("provenance missing: %q", version.Description)
+	}
+	if client := param(doc, "X-Client"); client.Schema.Enum != nil || client.Example != "cli" {
+		t.Errorf("agreeing variable treated as a conflict: %+v", client)
+	}
+	if len(doc.Warnings) != 0 {
+		t.Errorf("unexpected warnings: %v", doc.Warnings)
+	}
+
+	doc = build("")
+	if version := param(doc, "X-Api-Version"); version.Schema.Default != nil || version.Example != nil {
+		t.Errorf("default emitted without a selected environment: %+v", version)
+	}
+	if len(doc.Warnings) != 1 || doc.Warnings[0].Code != WarnEnvironmentConflict {
+		t.Errorf("expected an environment-conflict warning, got %v", doc.Warnings)
+	}
+}
+
+func TestLoadEnvironments(t *testing.T) {
+	fsys := fstest.MapFS{
+		"environments/prod.bru":  {Data: []byte("vars {\n  baseUrl: https://api.example.com\n}\n")},
+		"environments/local.bru": {Data: []byte("vars {\n  baseUrl: http://localhost:8080\n}\nvars:secret [\n  apiKey\n]\n")},
+		"users.bru":              {Data: []byte("get {\n  url: {{baseUrl}}/users\n}\n")},
+	}
+	envs, err := LoadEnvironments(fsys, ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(envs) != 2 || envs[0].Name != "local" || !envs[0].Secret["apiKey"] {
+		t.Fatalf("unexpected environments: %+v", envs)
+	}
+	env, err := FindEnvironment(envs, "prod")
+	if err != nil || env.Vars["baseUrl"] != "https://api.example.com" {
+		t.Errorf("FindEnvironment(prod) = %+v, %v", env, err)
+	}
+	if _, err := FindEnvironment(envs, "staging"); err == nil || !strings.Contains(err.Error(), "local, prod") {
+		t.Errorf("missing environment error should list the available ones: %v", err)
+	}
+
+	requests, err := CollectRequests(fsys, ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(requests) != 1 {
+		t.Errorf("environment files collected as requests: %d requests", len(requests))
+	}
+}
+
+func TestEnvironmentServers(t *testing.T) {
+	envs := []Environment{
+		{Name: "dev", Vars: map[string]string{"baseUrl": "http://localhost:8080/api"}},
+		{Name: "prod", Vars: map[string]string{"baseUrl": "https://{{region}}.api.example.com/api", "region": "eu"}},
+		{Name: "staging", Vars: map[string]string{"baseUrl": "https://{{region}}.api.example.com/api", "region": "eu"}},
+		{Name: "local", Vars: map[string]string{}},
+	}
+	requests := []Request{
+		{Method: "get", URL: "{{baseUrl}}/users", Name: "List Users"},
+		{Method: "get", URL: "https://status.example.com/health", Name: "Health"},
+		{Method: "get", URL: "{{baseUrl}}/orders", Name: "List Orders"},
+	}
+	doc := buildOpenAPI(requests, Options{Environments: envs})
+	want := []Server{
+		{URL: "http://localhost:8080/api", Description: "dev"},
+		{URL: "https://{region}.api.example.com/api", Description: "prod, staging", Variables: map[string]ServerVariable{"region": {Default: "eu"}}},
+		{URL: "{baseUrl}", Description: "local", Variables: map[string]ServerVariable{"baseUrl": {}}},
+	}
+	if !reflect.DeepEqual(doc.Servers, want) {
+		t.Errorf("got servers %+v, want %+v", doc.Servers, want)
+	}
+
+	if got := doc.Servers[1].expandedURL(); got != "https://eu.api.example.com/api" {
+		t.Errorf("expandedURL() = %q", got)
+	}
+}
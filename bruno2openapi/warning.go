@@ -0,0 +1,32 @@
+package bruno2openapi
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Warning is a non-fatal finding about the collection, reported on stderr
+// during conversion and listed by the lint subcommand.
+type Warning struct {
+	Code string
+	File string
+	// Line is the 1-based line in File the finding refers to, 0 when it
+	// is not tied to a line.
+	Line    int
+	Key     string
+	Message string
+}
+
+func (w Warning) String() string {
+	location := w.File
+	if w.Line > 0 {
+		location = fmt.Sprintf("%s:%d", w.File, w.Line)
+	}
+	if w.Key != "" {
+		location = strings.TrimSpace(fmt.Sprintf("%s (%s)", location, w.Key))
+	}
+	if location == "" {
+		return fmt.Sprintf("[%s] %s", w.Code, w.Message)
+	}
+	return fmt.Sprintf("[%s] %s: %s", w.Code, location, w.Message)
+}
@@ -0,0 +1,82 @@
+package bruno2openapi
+
+import (
+	"encoding/xml"
+	"strings"
+)
+
+type xmlElement struct {
+	name     string
+	children []*xmlElement
+}
+
+// inferXMLSchema derives a trivial schema from an example XML document:
+// the root element becomes an object named after it, elements with
+// children become nested objects, repeated elements arrays, and leaves
+// strings. A document that does not parse is documented as a string.
+func inferXMLSchema(body string) *MediaSchema {
+	root := parseXMLTree(body)
+	if root == nil {
+		return &MediaSchema{Type: "string"}
+	}
+	schema := xmlElementSchema(root)
+	schema.XML = &XMLObject{Name: root.name}
+	return schema
+}
+
+func parseXMLTree(body string) *xmlElement {
+	dec := xml.NewDecoder(strings.NewReader(body))
+	dec.Strict = false
+	var root *xmlElement
+	stack := []*xmlElement{}
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			if len(stack) > 0 {
+				return nil
+			}
+			return root
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			el := &xmlElement{name: t.Name.Local}
+			if len(stack) == 0 {
+				if root != nil {
+					return nil
+				}
+				root = el
+			} else {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, el)
+			}
+			stack = append(stack, el)
+		case xml.EndElement:
+			if len(stack) == 0 {
+				return nil
+			}
+			stack = stack[:len(stack)-1]
+		}
+	}
+}
+
+func xmlElementSchema(el *xmlElement) *MediaSchema {
+	if len(el.children) == 0 {
+		return &MediaSchema{Type: "string"}
+	}
+	count := map[string]int{}
+	for _, child := range el.children {
+		count[child.name]++
+	}
+	schema := &MediaSchema{Type: "object", Properties: map[string]*MediaSchema{}}
+	for _, child := range el.children {
+		if _, done := schema.Properties[child.name]; done {
+			continue
+		}
+		prop := xmlElementSchema(child)
+		if count[child.name] > 1 {
+			prop = &MediaSchema{Type: "array", Items: prop}
+		}
+		schema.Properties[child.name] = prop
+	}
+	return schema
+}
@@ -0,0 +1,134 @@
+package bruno2openapi
+
+import (
+	"bytes"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// MarshalYAML encodes doc as YAML without anchors or aliases, which
+// several downstream parsers reject. Vendor extensions are decoded into
+// plain values when copied, so aliases in their source are already
+// expanded by the time the node tree is built.
+func MarshalYAML(doc OpenAPI) ([]byte, error) {
+	return marshalDocument(doc, doc.PathOrder)
+}
+
+func marshalDocument(doc any, pathOrder []string) ([]byte, error) {
+	node, err := documentNode(doc, pathOrder)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(4)
+	if err := enc.Encode(node); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// documentNode encodes doc into a YAML node tree without anchors and with
+// its paths in pathOrder, ready to be written as YAML or JSON.
+func documentNode(doc any, pathOrder []string) (*yaml.Node, error) {
+	var node yaml.Node
+	if err := node.Encode(doc); err != nil {
+		return nil, err
+	}
+	stripAnchors(&node)
+	if len(pathOrder) > 0 {
+		orderPaths(&node, pathOrder)
+	}
+	return &node, nil
+}
+
+// stripAnchors replaces every alias with a copy of the node it refers to
+// and drops all anchor names.
+func stripAnchors(n *yaml.Node) {
+	if n.Kind == yaml.AliasNode && n.Alias != nil {
+		*n = *copyNode(n.Alias)
+	}
+	n.Anchor = ""
+	for _, child := range n.Content {
+		stripAnchors(child)
+	}
+}
+
+func copyNode(n *yaml.Node) *yaml.Node {
+	if n.Kind == yaml.AliasNode && n.Alias != nil {
+		return copyNode(n.Alias)
+	}
+	cp := *n
+	cp.Content = make([]*yaml.Node, len(n.Content))
+	for i, child := range n.Content {
+		cp.Content[i] = copyNode(child)
+	}
+	return &cp
+}
+
+// orderPaths rearranges the entries of the top-level paths mapping to
+// follow order. Paths missing from order keep their place at the end.
+func orderPaths(doc *yaml.Node, order []string) {
+	root := doc
+	if root.Kind == yaml.DocumentNode && len(root.Content) > 0 {
+		root = root.Content[0]
+	}
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value != "paths" {
+			continue
+		}
+		paths := root.Content[i+1]
+		rank := map[string]int{}
+		for n, name := range order {
+			rank[name] = n
+		}
+		type pair struct{ key, value *yaml.Node }
+		pairs := []pair{}
+		for j := 0; j+1 < len(paths.Content); j += 2 {
+			pairs = append(pairs, pair{paths.Content[j], paths.Content[j+1]})
+		}
+		sort.SliceStable(pairs, func(a, b int) bool {
+			ra, oka := rank[pairs[a].key.Value]
+			rb, okb := rank[pairs[b].key.Value]
+			if oka != okb {
+				return oka
+			}
+			return ra < rb
+		})
+		paths.Content = paths.Content[:0]
+		for _, p := range pairs {
+			paths.Content = append(paths.Content, p.key, p.value)
+		}
+		return
+	}
+}
+
+// orderedMapping encodes values in the given key order followed by the
+// extensions sorted by name.
+func orderedMapping(order []string, values, extensions map[string]any) (*yaml.Node, error) {
+	out := &yaml.Node{Kind: yaml.MappingNode}
+	add := func(key string, value any) error {
+		var v yaml.Node
+		if err := v.Encode(value); err != nil {
+			return err
+		}
+		out.Content = append(out.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, &v)
+		return nil
+	}
+	for _, key := range order {
+		if err := add(key, values[key]); err != nil {
+			return nil, err
+		}
+	}
+	for _, key := range sortedKeys(extensions) {
+		if err := add(key, extensions[key]); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
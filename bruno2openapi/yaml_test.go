@@ -0,0 +1,97 @@
+package bruno2openapi
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"gopkg.in/yaml.v3"
+)
+
+var anchorOrAlias = regexp.MustCompile(`(?m)(^|[\s:-])[&*][A-Za-z0-9_-]+\s*$|(^|[\s:-])&[A-Za-z0-9_-]+\s`)
+
+func TestMarshalYAMLHasNoAnchors(t *testing.T) {
+	// Identical bodies, folder defaults and GraphQL schemas across many
+	// requests maximise the number of equal values in the document.
+	fsys := fstest.MapFS{
+		"shop/folder.bru": {Data: []byte("vars:pre-request {\n  region: eu\n}\n")},
+	}
+	for _, name := range []string{"a", "b", "c"} {
+		fsys["shop/"+name+".bru"] = &fstest.MapFile{Data: []byte(`post {
+  url: {{baseUrl}}/` + name + `?region={{region}}
+}
+
+body:json {
+  {"items": [{"sku": "x"}], "region": "eu"}
+}
+`)}
+	}
+	requests, err := CollectRequests(fsys, ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := buildOpenAPI(requests, Options{})
+
+	// Share one schema pointer between operations and carry a yaml.Node
+	// that uses an anchor and an alias, as pass-through extensions may.
+	shared := &MediaSchema{Type: "object"}
+	for _, item := range doc.Paths {
+		for method, op := range item.Operations {
+			op.RequestBody.Content["application/json"] = MediaType{Schema: shared}
+			item.Operations[method] = op
+		}
+	}
+	var node yaml.Node
+	if err := yaml.Unmarshal([]byte("base: &base {type: string}\ncopy: *base\n"), &node); err != nil {
+		t.Fatal(err)
+	}
+	doc.Paths["/a"].Extensions = map[string]any{"x-shared": node.Content[0]}
+
+	out, err := MarshalYAML(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loc := anchorOrAlias.FindString(string(out)); loc != "" {
+		t.Errorf("output contains an anchor or alias %q:\n%s", strings.TrimSpace(loc), out)
+	}
+	if !strings.Contains(string(out), "copy: {type: string}") {
+		t.Errorf("alias was not expanded:\n%s", out)
+	}
+}
+
+func TestMarshalJSONMatchesYAML(t *testing.T) {
+	requests := []Request{
+		{Method: "post", URL: "{{baseUrl}}/orders", Name: "Create Order", File: "create.bru", BodyType: "json", Body: `{"qty": 2, "note": null, "tags": ["a"]}`, Sunset: "2025-06-30"},
+		{Method: "get", URL: "{{baseUrl}}/orders/:id", Name: "Get Order", File: "get.bru", Status: "201"},
+	}
+	doc, err := Build(requests, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	yamlOut, err := MarshalYAML(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jsonOut, err := MarshalJSON(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(jsonOut), "{\n  \"openapi\": \"3.0.0\",\n  \"info\"") {
+		t.Errorf("keys not in document order:\n%s", jsonOut)
+	}
+
+	var fromYAML, fromJSON any
+	if err := yaml.Unmarshal(yamlOut, &fromYAML); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(jsonOut, &fromJSON); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, jsonOut)
+	}
+	a, _ := json.Marshal(fromYAML)
+	b, _ := json.Marshal(fromJSON)
+	if string(a) != string(b) {
+		t.Errorf("JSON and YAML differ:\n%s\n%s", a, b)
+	}
+}
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"bruno-openapi/bruno2openapi"
+)
+
+const (
+	checkPass = "pass"
+	checkWarn = "warn"
+	checkFail = "fail"
+)
+
+// doctorCheck is the outcome of one preflight check; hint tells the user
+// how to fix a warning or failure.
+type doctorCheck struct {
+	name   string
+	status string
+	detail string
+	hint   string
+}
+
+// runDoctor implements `bruno-to-openapi doctor`: quick preflight checks
+// on the collection and output location. It exits non-zero when any check
+// fails; warnings are reported but do not fail.
+func runDoctor(args []string) int {
+	fset := flag.NewFlagSet("doctor", flag.ExitOnError)
+	inputDir := fset.String("i", "", "Path ke folder Bruno collection")
+	outputFile := fset.String("o", DefaultOutput, "Path output OpenAPI YAML yang akan diperiksa")
+	fset.Parse(args)
+
+	if strings.TrimSpace(*inputDir) == "" {
+		fmt.Println("Error: input directory wajib diisi dengan -i <path>")
+		return 1
+	}
+
+	failed := 0
+	for _, check := range doctorChecks(*inputDir, *outputFile) {
+		fmt.Printf("%-4s  %s: %s\n", strings.ToUpper(check.status), check.name, check.detail)
+		if check.hint != "" && check.status != checkPass {
+			fmt.Println("      →", check.hint)
+		}
+		if check.status == checkFail {
+			failed++
+		}
+	}
+	if failed > 0 {
+		fmt.Printf("❌ doctor found %d failing check(s)\n", failed)
+		return 1
+	}
+	return 0
+}
+
+func doctorChecks(inputDir, outputFile string) []doctorCheck {
+	if info, err := os.Stat(inputDir); err != nil || !info.IsDir() {
+		return []doctorCheck{{
+			name:   "collection",
+			status: checkFail,
+			detail: inputDir + " is not a directory",
+			hint:   "pass the folder that contains bruno.json with -i",
+		}}
+	}
+	return []doctorCheck{
+		checkBrunoJSON(inputDir),
+		checkEnvironments(inputDir),
+		checkLayout(inputDir),
+		checkOutput(outputFile),
+		checkSpecSize(inputDir),
+	}
+}
+
+func checkBrunoJSON(inputDir string) doctorCheck {
+	check := doctorCheck{name: "bruno.json"}
+	if _, err := os.Stat(filepath.Join(inputDir, "bruno.json")); err != nil {
+		check.status = checkWarn
+		check.detail = "not found in " + inputDir
+		check.hint = "point -i at the collection root, the folder Bruno opens"
+		return check
+	}
+	cfg, err := bruno2openapi.ReadConfig(os.DirFS(inputDir), ".")
+	if err != nil {
+		check.status = checkFail
+		check.detail = err.Error()
+		check.hint = "fix the JSON syntax; Bruno cannot open the collection either"
+		return check
+	}
+	if warnings := cfg.Warnings(); len(warnings) > 0 {
+		check.status = checkWarn
+		check.detail = warnings[0].Message
+		check.hint = "check for a newer bruno-to-openapi release"
+		return check
+	}
+	check.status = checkPass
+	check.detail = fmt.Sprintf("collection %q, format version %s", cfg.Name, cfg.Version)
+	return check
+}
+
+func checkEnvironments(inputDir string) doctorCheck {
+	check := doctorCheck{name: "environments"}
+	envs, err := bruno2openapi.LoadEnvironments(os.DirFS(inputDir), ".")
+	if err != nil {
+		check.status = checkFail
+		check.detail = err.Error()
+		check.hint = "fix or remove the environment file"
+		return check
+	}
+	if len(envs) == 0 {
+		check.status = checkWarn
+		check.detail = "no environments/*.bru files"
+		check.hint = "without an environment, {{baseUrl}} and other variables stay unresolved"
+		return check
+	}
+	names := []string{}
+	for _, env := range envs {
+		names = append(names, env.Name)
+	}
+	check.status = checkPass
+	check.detail = fmt.Sprintf("%d environment(s) parsed: %s (select one with --env)", len(envs), strings.Join(names, ", "))
+	return check
+}
+
+// checkLayout looks for .bru files that are converted although Bruno
+// would not pick them up: inside hidden folders or node_modules that are
+// not on the bruno.json ignore list.
+func checkLayout(inputDir string) doctorCheck {
+	check := doctorCheck{name: "layout"}
+	cfg, _ := bruno2openapi.ReadConfig(os.DirFS(inputDir), ".")
+	stray := []string{}
+	filepath.WalkDir(inputDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		rel, _ := filepath.Rel(inputDir, path)
+		if d.IsDir() {
+			if path != inputDir && cfg.SkipsDir(filepath.ToSlash(rel)) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(d.Name(), ".bru") {
+			return nil
+		}
+		for _, part := range strings.Split(filepath.ToSlash(filepath.Dir(rel)), "/") {
+			if part == "node_modules" || (strings.HasPrefix(part, ".") && part != ".") {
+				stray = append(stray, rel)
+				break
+			}
+		}
+		return nil
+	})
+	if len(stray) > 0 {
+		check.status = checkWarn
+		check.detail = fmt.Sprintf("%d .bru file(s) outside the collection layout, e.g. %s", len(stray), stray[0])
+		check.hint = "these are converted too; move or delete them if they are not part of the API"
+		return check
+	}
+	check.status = checkPass
+	check.detail = "all .bru files are in collection folders"
+	return check
+}
+
+func checkOutput(outputFile string) doctorCheck {
+	check := doctorCheck{name: "output"}
+	if err := checkOutputFile(outputFile, false); err != nil {
+		check.status = checkFail
+		check.detail = err.Error()
+		check.hint = "choose another -o path or pass --mkdir when converting"
+		return check
+	}
+	check.status = checkPass
+	check.detail = outputFile + " is writable"
+	return check
+}
+
+// checkSpecSize runs the conversion in memory and reports the size of
+// the resulting spec. Any .bru syntax problem fails the check.
+func checkSpecSize(inputDir string) doctorCheck {
+	check := doctorCheck{name: "conversion"}
+	requests, warnings, err := loadCollection(inputDir, nil)
+	syntax := []bruno2openapi.Warning{}
+	for _, w := range warnings {
+		if w.Code == bruno2openapi.WarnSyntax {
+			syntax = append(syntax, w)
+		}
+	}
+	if err == nil && len(syntax) > 0 {
+		skipped := 0
+		for _, req := range requests {
+			if req.Skipped {
+				skipped++
+			}
+		}
+		check.status = checkFail
+		check.detail = fmt.Sprintf("%d .bru syntax problem(s), %d file(s) skipped, e.g. %s:%d: %s",
+			len(syntax), skipped, syntax[0].File, syntax[0].Line, syntax[0].Message)
+		check.hint = "run lint for the full list and fix the reported files"
+		return check
+	}
+	if err == nil {
+		var openapi bruno2openapi.OpenAPI
+		if openapi, err = bruno2openapi.Build(requests, bruno2openapi.NewDefaultOptions()); err == nil {
+			var spec []byte
+			if spec, err = bruno2openapi.MarshalYAML(openapi); err == nil {
+				check.status = checkPass
+				check.detail = fmt.Sprintf("%d request(s), %d path(s), estimated spec size %s",
+					countRequests(requests), len(openapi.Paths), formatSize(len(spec)))
+				return check
+			}
+		}
+	}
+	check.status = checkFail
+	check.detail = err.Error()
+	check.hint = "run lint for details, or fix the reported file"
+	return check
+}
+
+func formatSize(n int) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MiB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KiB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", n)
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDoctorChecks(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("bruno.json", `{"name": "api"}`)
+	write("environments/local.bru", "vars {\n  baseUrl: http://localhost\n}\n")
+	write("users/list.bru", "get {\n  url: {{baseUrl}}/users\n}\n")
+	write(".old/stale.bru", "get {\n  url: /stale\n}\n")
+	write("node_modules/pkg/fixture.bru", "get {\n  url: /fixture\n}\n")
+
+	status := map[string]string{}
+	for _, check := range doctorChecks(dir, filepath.Join(dir, "missing", "openapi.yml")) {
+		status[check.name] = check.status
+		if check.name == "layout" && !strings.HasPrefix(check.detail, "1 .bru file(s)") {
+			t.Errorf("layout counted ignored folders: %s", check.detail)
+		}
+	}
+	want := map[string]string{
+		"bruno.json":   checkPass,
+		"environments": checkPass,
+		"layout":       checkWarn,
+		"output":       checkFail,
+		"conversion":   checkPass,
+	}
+	for name, s := range want {
+		if status[name] != s {
+			t.Errorf("%s: got %q, want %q", name, status[name], s)
+		}
+	}
+}
+
+func TestDoctorFailsOnSkippedFile(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("bruno.json", `{"name": "api"}`)
+	write("users/get.bru", "get {\n  url: /users/:id\n}\n")
+	write("users/list.bru", "meta {\n  name: List\n}\n\nget {\n  url: /users\n")
+
+	check := checkSpecSize(dir)
+	if check.status != checkFail || !strings.Contains(check.detail, "1 file(s) skipped") || !strings.Contains(check.detail, "users/list.bru:5") {
+		t.Errorf("got %s: %s", check.status, check.detail)
+	}
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"bruno-openapi/bruno2openapi"
+)
+
+const (
+	// progressThreshold is the number of files above which parsing
+	// progress is reported.
+	progressThreshold = 200
+	textProgressEvery = 100 * time.Millisecond
+	jsonProgressEvery = time.Second
+)
+
+// logger writes warnings, progress and summaries to stderr, either as
+// human-readable text or as one JSON event per line (--log-format json).
+type logger struct {
+	mu         sync.Mutex
+	out        io.Writer
+	json       bool
+	progress   bool
+	lastUpdate time.Time
+}
+
+func newLogger(format string, noProgress bool) (*logger, error) {
+	if format != "text" && format != "json" {
+		return nil, fmt.Errorf("unknown log format %q (want text or json)", format)
+	}
+	l := &logger{out: os.Stderr, json: format == "json", progress: !noProgress}
+	// Interactive progress lines only make sense on a terminal; JSON
+	// progress events are meant for machines and are always emitted.
+	if !l.json && !isTerminal(os.Stderr) {
+		l.progress = false
+	}
+	return l, nil
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+func (l *logger) event(fields map[string]any) {
+	line, _ := json.Marshal(fields)
+	fmt.Fprintln(l.out, string(line))
+}
+
+func (l *logger) warning(w bruno2openapi.Warning) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.json {
+		event := map[string]any{"event": "warning", "code": w.Code, "file": w.File, "key": w.Key, "message": w.Message}
+		if w.Line > 0 {
+			event["line"] = w.Line
+		}
+		l.event(event)
+		return
+	}
+	fmt.Fprintln(l.out, "Warning:", w)
+}
+
+// progressFunc returns a throttled progress reporter, or nil when
+// progress is disabled.
+func (l *logger) progressFunc() bruno2openapi.ProgressFunc {
+	if !l.progress {
+		return nil
+	}
+	return func(done, total int) {
+		if total <= progressThreshold {
+			return
+		}
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		every := textProgressEvery
+		if l.json {
+			every = jsonProgressEvery
+		}
+		if done < total && time.Since(l.lastUpdate) < every {
+			return
+		}
+		l.lastUpdate = time.Now()
+		if l.json {
+			l.event(map[string]any{"event": "progress", "done": done, "total": total})
+			return
+		}
+		fmt.Fprintf(l.out, "\rParsing .bru files: %d/%d", done, total)
+		if done == total {
+			fmt.Fprintln(l.out)
+		}
+	}
+}
+
+// rebuilding announces that watch mode detected a change and started a
+// new conversion.
+func (l *logger) rebuilding() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.json {
+		l.event(map[string]any{"event": "rebuild"})
+		return
+	}
+	fmt.Println("🔄 Change detected, regenerating...")
+}
+
+// summary reports a finished conversion with the parse and build phase
+// timings.
+func (l *logger) summary(output string, files, ignored int, parse, build time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.json {
+		l.event(map[string]any{
+			"event":   "summary",
+			"output":  output,
+			"files":   files,
+			"ignored": ignored,
+			"parseMs": parse.Milliseconds(),
+			"buildMs": build.Milliseconds(),
+		})
+		return
+	}
+	fmt.Printf("✅ OpenAPI generated: %s (%d files, %d ignored, parse %s, build %s)\n",
+		output, files, ignored, parse.Round(time.Millisecond), build.Round(time.Millisecond))
+}
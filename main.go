@@ -1,550 +1,527 @@
 package main
 
 import (
-	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
-	"io/fs"
-	"net/url"
+	"net/http"
 	"os"
 	"path/filepath"
-	"regexp"
+	"sort"
 	"strings"
+	"time"
 
-	"gopkg.in/yaml.v3"
+	"bruno-openapi/bruno2openapi"
 )
 
 const (
 	DefaultOutput = "./openapi.yml"
 )
 
-type Request struct {
-	Method      string
-	URL         string
-	Headers     map[string]string
-	Query       map[string]string
-	PathParams  map[string]string
-	Body        string
-	BodyType    string
-	Name        string
-	Tag         string
-	Description string
-}
-
-type OpenAPI struct {
-	OpenAPI string                          `yaml:"openapi"`
-	Info    Info                            `yaml:"info"`
-	Servers []Server                        `yaml:"servers,omitempty"`
-	Paths   map[string]map[string]Operation `yaml:"paths"`
-}
-
-type Info struct {
-	Title   string `yaml:"title"`
-	Version string `yaml:"version"`
-}
-
-type Server struct {
-	URL string `yaml:"url"`
-}
-
-type Operation struct {
-	Summary     string              `yaml:"summary,omitempty"`
-	Description string              `yaml:"description,omitempty"`
-	Tags        []string            `yaml:"tags,omitempty"`
-	Parameters  []Parameter         `yaml:"parameters,omitempty"`
-	RequestBody *RequestBody        `yaml:"requestBody,omitempty"`
-	Responses   map[string]Response `yaml:"responses"`
-}
-
-type Parameter struct {
-	Name     string `yaml:"name"`
-	In       string `yaml:"in"`
-	Required bool   `yaml:"required"`
-	Schema   Schema `yaml:"schema"`
-	Example  any    `yaml:"example,omitempty"`
-}
-
-type Schema struct {
-	Type string `yaml:"type,omitempty"`
-}
-
-type RequestBody struct {
-	Required bool                 `yaml:"required"`
-	Content  map[string]MediaType `yaml:"content"`
-}
-
-type MediaType struct {
-	Schema  *MediaSchema `yaml:"schema,omitempty"`
-	Example any          `yaml:"example,omitempty"`
-}
-
-type MediaSchema struct {
-	Type string `yaml:"type"`
-}
+// Values accepted by -f.
+const (
+	formatYAML = "yaml"
+	formatJSON = "json"
+)
 
-type Response struct {
-	Description string `yaml:"description"`
-}
+// Values accepted by --spec-version.
+const (
+	specVersion3 = "3.0"
+	specVersion2 = "2.0"
+)
 
-var sectionRegex = regexp.MustCompile(`^([\w-]+)(?::([\w-]+))?\s*\{$`)
-var pathParamRegex = regexp.MustCompile(`\{([A-Za-z0-9_]+)\}`)
-
-func parseBru(content string) Request {
-	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
-	result := Request{
-		Method:     "get",
-		Headers:    map[string]string{},
-		Query:      map[string]string{},
-		PathParams: map[string]string{},
-		Name:       "Unnamed",
-	}
-
-	section := ""
-	sectionType := ""
-	buffer := []string{}
-	bodyDepth := 0
-
-	isMethodBlock := func(name string) bool {
-		switch name {
-		case "get", "post", "put", "patch", "delete", "options", "head":
-			return true
-		default:
-			return false
-		}
+// loadCollection parses every .bru file under inputDir and returns the
+// requests together with any warnings found along the way.
+func loadCollection(inputDir string, progress bruno2openapi.ProgressFunc) ([]bruno2openapi.Request, []bruno2openapi.Warning, error) {
+	fsys := os.DirFS(inputDir)
+	cfg, err := bruno2openapi.ReadConfig(fsys, ".")
+	if err != nil {
+		return nil, nil, err
 	}
-
-	flushBuffer := func() {
-		if section == "body" && len(buffer) > 0 {
-			raw := strings.TrimSpace(strings.Join(buffer, "\n"))
-			if raw != "" {
-				result.Body = raw
-			}
-		} else if section == "docs" && len(buffer) > 0 {
-			raw := strings.TrimSpace(strings.Join(buffer, "\n"))
-			if raw != "" {
-				result.Description = raw
-			}
-		}
-		buffer = []string{}
+	requests, err := bruno2openapi.CollectRequestsProgress(fsys, ".", progress)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	for _, rawLine := range lines {
-		line := strings.TrimSpace(rawLine)
-		if line == "" {
-			continue
-		}
-
-		if match := sectionRegex.FindStringSubmatch(line); match != nil {
-			flushBuffer()
-			name := strings.ToLower(match[1])
-			typeName := ""
-			if len(match) > 2 {
-				typeName = strings.ToLower(match[2])
-			}
-
-			if isMethodBlock(name) {
-				section = "method"
-				sectionType = name
-				result.Method = name
-			} else if name == "meta" {
-				section = "meta"
-				sectionType = ""
-			} else if name == "headers" {
-				section = "headers"
-				sectionType = ""
-			} else if name == "query" {
-				section = "query"
-				sectionType = ""
-			} else if name == "params" {
-				if typeName == "query" {
-					section = "params_query"
-				} else {
-					section = "params"
-				}
-				sectionType = typeName
-			} else if name == "body" {
-				section = "body"
-				sectionType = typeName
-				result.BodyType = typeName
-				bodyDepth = 1
-			} else if name == "docs" {
-				section = "docs"
-				sectionType = ""
-				bodyDepth = 1
-			} else {
-				section = "ignore"
-				sectionType = ""
-			}
-			_ = sectionType
-			continue
-		}
-
-		if section == "body" || section == "docs" {
-			// Count all braces in the line to track nesting depth
-			for _, ch := range rawLine {
-				if ch == '{' {
-					bodyDepth++
-				} else if ch == '}' {
-					bodyDepth--
-				}
-			}
-			if bodyDepth <= 0 {
-				flushBuffer()
-				section = ""
-				sectionType = ""
-				bodyDepth = 0
-				continue
-			}
-			buffer = append(buffer, rawLine)
-			continue
-		}
-
-		if line == "}" {
-			flushBuffer()
-			section = ""
-			sectionType = ""
-			bodyDepth = 0
-			continue
-		}
+	warnings := cfg.Warnings()
+	for _, req := range requests {
+		warnings = append(warnings, bruno2openapi.Lint(req)...)
+	}
+	return requests, warnings, nil
+}
 
-		switch section {
-		case "meta":
-			k, v := splitKeyValue(line)
-			if k == "name" {
-				result.Name = v
-			} else if k == "method" {
-				result.Method = strings.ToLower(v)
-			} else if k == "url" {
-				setURL(&result, v)
-			}
-		case "method":
-			k, v := splitKeyValue(line)
-			if k == "url" {
-				setURL(&result, v)
-			}
-		case "headers":
-			k, v := splitKeyValue(line)
-			if k != "" {
-				result.Headers[k] = v
-			}
-		case "query":
-			k, v := splitKeyValue(line)
-			if k != "" {
-				result.Query[k] = v
-			}
-		case "params_query":
-			k, v := splitKeyValue(line)
-			if k != "" {
-				result.Query[k] = v
-			}
-		case "params":
-			k, v := splitKeyValue(line)
-			if k != "" {
-				result.PathParams[k] = v
-			}
+func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "lint":
+			os.Exit(runLint(os.Args[2:]))
+		case "doctor":
+			os.Exit(runDoctor(os.Args[2:]))
 		}
 	}
 
-	flushBuffer()
-	return result
-}
+	defaults := bruno2openapi.NewDefaultOptions()
+	inputDir := flag.String("i", "", "Path ke folder Bruno collection")
+	outputFile := flag.String("o", DefaultOutput, "Path output OpenAPI YAML")
+	title := flag.String("title", "", "Judul API di blok info (default: openapi.title atau name di bruno.json)")
+	apiVersion := flag.String("api-version", "", "Versi API di blok info (default: openapi.version di bruno.json atau "+bruno2openapi.DefaultVersion+")")
+	description := flag.String("description", "", "Deskripsi API di blok info (default: openapi.description di bruno.json atau docs collection.bru)")
+	termsOfService := flag.String("terms-of-service", "", "URL syarat layanan (termsOfService) di blok info")
+	contactName := flag.String("contact-name", "", "Nama kontak di blok info")
+	contactEmail := flag.String("contact-email", "", "Email kontak di blok info")
+	contactURL := flag.String("contact-url", "", "URL kontak di blok info")
+	license := flag.String("license", "", "Nama atau identifier SPDX lisensi di blok info, mis. Apache-2.0")
+	licenseURL := flag.String("license-url", "", "URL teks lisensi di blok info")
+	watchMode := flag.Bool("watch", false, "Pantau perubahan file .bru dan generate ulang otomatis")
+	serveAddr := flag.String("serve", "", "Sajikan spec dan Swagger UI di alamat ini, mis. :8080")
+	operationIDStrategy := flag.String("operation-id", defaults.OperationIDStrategy, "Sumber operationId: requestName (nama request), methodPath (method dan path) atau folderAndName (folder dan nama request)")
+	operationIDStyle := flag.String("operation-id-style", defaults.OperationIDStyle, "Gaya operationId: camel, snake atau kebab")
+	tagStrategy := flag.String("tag-strategy", defaults.TagStrategy, "Penamaan tag dari folder: path (path folder lengkap, mis. admin/users) atau groups (nama folder, dikelompokkan per folder teratas dalam x-tagGroups)")
+	tagDepth := flag.Int("tag-depth", defaults.TagDepth, "Jumlah level folder yang dipakai sebagai tag (0 = seluruh path folder)")
+	headerIgnore := flag.String("header-ignore", "", "Daftar header (dipisah koma) yang tidak didokumentasikan sebagai parameter")
+	foldProbes := flag.Bool("fold-probe-methods", false, "Gabungkan request HEAD/OPTIONS ke operasi saudaranya (x-cors) alih-alih ditulis terpisah")
+	basePathMode := flag.String("base-path-mode", defaults.BasePathMode, "Letak path dari base URL (mis. /api/v1): server atau path")
+	strict := flag.Bool("strict", false, "Gagal (exit non-zero) jika ada kesalahan sintaks .bru atau validasi spec menemukan masalah")
+	logFormat := flag.String("log-format", "text", "Format log di stderr: text atau json")
+	noProgress := flag.Bool("no-progress", false, "Jangan tampilkan progress parsing")
+	mkdir := flag.Bool("mkdir", false, "Buat folder output jika belum ada")
+	graphqlContentType := flag.String("graphql-content-type", defaults.GraphQLContentType, "Media type body GraphQL: application/json atau application/graphql")
+	descriptionTemplate := flag.String("operation-description-template", "", "Template deskripsi untuk request tanpa blok docs, mis. \"Performs {method} on {path}.\" (token: {"+strings.Join(bruno2openapi.DescriptionTokens(), "}, {")+"})")
+	envName := flag.String("env", "", "Nama environment (environments/<nama>.bru) untuk mengisi {{variabel}} di URL, header dan body")
+	format := flag.String("f", "", "Format output: yaml atau json (default: json jika -o berakhiran .json, selain itu yaml)")
+	specVersion := flag.String("spec-version", specVersion3, "Versi spec output: 3.0 (OpenAPI 3) atau 2.0 (Swagger 2.0)")
+	ordering := flag.String("order", defaults.Ordering, "Urutan paths dan operasi: path (alfabetis) atau seq (mengikuti meta seq di collection)")
+	emitScripts := flag.Bool("emit-scripts", false, "Sertakan blok script:pre-request/post-response sebagai ekstensi x-bruno-scripts")
+	bodyRequired := flag.String("body-required", defaults.BodyRequired, "requestBody.required: auto (wajib untuk POST/PUT/PATCH dengan body tidak kosong), always atau never")
+	requiredProperties := flag.String("required-properties", defaults.RequiredProperties, "Daftar required pada schema yang disimpulkan dari body JSON: none, all (semua field contoh) atau intersection (field yang ada di semua contoh)")
+	skipUnexpectedBodies := flag.Bool("skip-unexpected-bodies", false, "Abaikan body pada request GET/HEAD/DELETE (dengan warning) alih-alih mendokumentasikannya")
+	keepCookieHeader := flag.Bool("keep-cookie-header", false, "Dokumentasikan header Cookie apa adanya alih-alih memecahnya menjadi parameter cookie")
+	keepContentTypeParams := flag.Bool("keep-content-type-params", false, "Pertahankan parameter Content-Type (mis. charset=utf-8) pada media type request body")
+	extraMethods := flag.String("extra-methods", "", "Daftar method non-standar (dipisah koma, mis. purge,link) yang ditulis sebagai ekstensi x-<method>")
+	baseURL := flag.String("base-url", "", "Base URL untuk request dengan URL relatif (/users); default {{baseUrl}} jika variabel itu ada")
+	trailingSlash := flag.String("trailing-slash", defaults.TrailingSlash, "Garis miring di akhir path: strip (/users/ jadi /users) atau keep")
+	onConflict := flag.String("on-conflict", defaults.OnConflict, "Jika dua request punya path dan method yang sama: merge (gabungkan parameter dan contoh body), keep-first, suffix atau error")
+	noTypeInference := flag.Bool("no-type-inference", false, "Jangan tebak tipe query parameter (integer, number, boolean, date) dari contoh nilainya")
+	includeDisabled := flag.Bool("include-disabled", false, "Tetap dokumentasikan header, query dan params yang dinonaktifkan (~) dengan keterangan disabled")
+	unresolved := flag.String("unresolved", defaults.Unresolved, "Perlakuan {{variabel}} tanpa nilai di contoh parameter, header dan body: keep, blank atau error")
+	varValues := varFlag{}
+	envServers := flag.Bool("env-servers", false, "Tulis satu server per environment (environments/*.bru) dengan variabel server untuk {{placeholder}} di dalamnya")
+	flag.Var(varValues, "var", "Nilai variabel nama=nilai untuk mengisi {{nama}}; bisa diulang dan menimpa nilai environment")
+	flag.Parse()
 
-func splitKeyValue(line string) (string, string) {
-	parts := strings.Split(line, ":")
-	if len(parts) == 0 {
-		return "", ""
+	if strings.TrimSpace(*inputDir) == "" {
+		fmt.Println("Error: input directory wajib diisi dengan -i <path>")
+		os.Exit(1)
 	}
-	key := strings.TrimSpace(parts[0])
-	value := strings.TrimSpace(strings.Join(parts[1:], ":"))
-	return key, value
-}
-
-func setURL(req *Request, raw string) {
-	cleaned, query := extractQueryFromURL(raw)
-	req.URL = cleaned
-	for k, v := range query {
-		if _, exists := req.Query[k]; !exists {
-			req.Query[k] = v
+	opts := defaults
+	if cfg, err := bruno2openapi.ReadConfig(os.DirFS(*inputDir), "."); err == nil {
+		if cfg.Name != "" {
+			opts.Title = cfg.Name
 		}
+		if cfg.OpenAPI.Title != "" {
+			opts.Title = cfg.OpenAPI.Title
+		}
+		if cfg.OpenAPI.Version != "" {
+			opts.Version = cfg.OpenAPI.Version
+		}
+		opts.Description = cfg.OpenAPI.Description
+		opts.TermsOfService = cfg.OpenAPI.TermsOfService
+		opts.Contact = cfg.OpenAPI.Contact
+		opts.License = cfg.OpenAPI.License
 	}
-}
-
-func extractQueryFromURL(raw string) (string, map[string]string) {
-	trimmed := strings.TrimSpace(raw)
-	if trimmed == "" {
-		return raw, map[string]string{}
+	if *title != "" {
+		opts.Title = *title
 	}
-	parts := strings.SplitN(trimmed, "?", 2)
-	if len(parts) < 2 {
-		return raw, map[string]string{}
+	if *apiVersion != "" {
+		opts.Version = *apiVersion
 	}
-	query := map[string]string{}
-	values, err := url.ParseQuery(parts[1])
-	if err != nil {
-		return parts[0], query
+	if *description != "" {
+		opts.Description = *description
 	}
-	for k, v := range values {
-		if len(v) > 0 {
-			query[k] = v[0]
-		} else {
-			query[k] = ""
-		}
+	if *termsOfService != "" {
+		opts.TermsOfService = *termsOfService
 	}
-	return parts[0], query
-}
-
-func buildOpenAPI(requests []Request) OpenAPI {
-	paths := map[string]map[string]Operation{}
-	serverSet := map[string]bool{}
-
-	for _, req := range requests {
-		pathName, server := splitURL(req.URL)
-		normalizedPath := normalizePathParams(pathName)
-
-		if server != "" {
-			serverSet[server] = true
-		}
-		if _, ok := paths[normalizedPath]; !ok {
-			paths[normalizedPath] = map[string]Operation{}
+	if *contactName != "" || *contactEmail != "" || *contactURL != "" {
+		contact := bruno2openapi.Contact{}
+		if opts.Contact != nil {
+			contact = *opts.Contact
 		}
-
-		parameters := []Parameter{}
-		for name, value := range req.Query {
-			parameters = append(parameters, Parameter{
-				Name:     name,
-				In:       "query",
-				Required: false,
-				Schema:   Schema{Type: "string"},
-				Example:  value,
-			})
+		if *contactName != "" {
+			contact.Name = *contactName
 		}
-		for name, value := range req.PathParams {
-			parameters = append(parameters, Parameter{
-				Name:     name,
-				In:       "path",
-				Required: true,
-				Schema:   Schema{Type: "string"},
-				Example:  value,
-			})
+		if *contactEmail != "" {
+			contact.Email = *contactEmail
 		}
-
-		for _, name := range extractPathParams(normalizedPath) {
-			if !hasPathParam(parameters, name) {
-				parameters = append(parameters, Parameter{
-					Name:     name,
-					In:       "path",
-					Required: true,
-					Schema:   Schema{Type: "string"},
-				})
-			}
+		if *contactURL != "" {
+			contact.URL = *contactURL
 		}
-
-		op := Operation{
-			Summary:     req.Name,
-			Description: req.Description,
-			Responses:   map[string]Response{"200": {Description: "Success"}},
+		opts.Contact = &contact
+	}
+	if *license != "" {
+		opts.License = &bruno2openapi.License{Name: *license, URL: *licenseURL}
+	} else if *licenseURL != "" && opts.License != nil {
+		opts.License = &bruno2openapi.License{Name: opts.License.Name, URL: *licenseURL}
+	}
+	opts.GraphQLContentType = *graphqlContentType
+	opts.OperationIDStyle = *operationIDStyle
+	opts.OperationIDStrategy = *operationIDStrategy
+	opts.TagDepth = *tagDepth
+	opts.TagStrategy = *tagStrategy
+	opts.HeaderIgnore = splitList(*headerIgnore)
+	opts.FoldProbeMethods = *foldProbes
+	opts.BasePathMode = *basePathMode
+	opts.DescriptionTemplate = *descriptionTemplate
+	opts.EmitScripts = *emitScripts
+	opts.IncludeDisabled = *includeDisabled
+	opts.NoTypeInference = *noTypeInference
+	opts.OnConflict = *onConflict
+	opts.TrailingSlash = *trailingSlash
+	opts.BaseURL = *baseURL
+	opts.ExtraMethods = splitList(*extraMethods)
+	opts.KeepContentTypeParams = *keepContentTypeParams
+	opts.KeepCookieHeader = *keepCookieHeader
+	opts.BodyRequired = *bodyRequired
+	opts.RequiredProperties = *requiredProperties
+	opts.SkipUnexpectedBodies = *skipUnexpectedBodies
+	opts.Ordering = *ordering
+	if *envName != "" {
+		vars, err := environmentVariables(*inputDir, *envName)
+		if err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
 		}
-		if req.Tag != "" {
-			op.Tags = []string{req.Tag}
+		opts.Variables = vars
+	}
+	if len(varValues) > 0 {
+		if opts.Variables == nil {
+			opts.Variables = bruno2openapi.NewVariables()
 		}
-		if len(parameters) > 0 {
-			op.Parameters = parameters
+		for name, value := range varValues {
+			opts.Variables.Set(name, value, false)
 		}
-		if rb := buildRequestBody(req); rb != nil {
-			op.RequestBody = rb
+	}
+	if *envServers {
+		envs, err := bruno2openapi.LoadEnvironments(os.DirFS(*inputDir), ".")
+		if err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
 		}
-
-		paths[normalizedPath][req.Method] = op
+		opts.Environments = envs
 	}
-
-	servers := []Server{}
-	for url := range serverSet {
-		servers = append(servers, Server{URL: url})
+	opts.Unresolved = *unresolved
+	if err := opts.Validate(); err != nil {
+		fmt.Println("Error:", flagError(err))
+		os.Exit(1)
 	}
-
-	openapi := OpenAPI{
-		OpenAPI: "3.0.0",
-		Info: Info{
-			Title:   "API from Bruno",
-			Version: "1.0.0",
-		},
-		Paths: paths,
+	outputFormat, err := resolveFormat(*format, *outputFile)
+	if err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
 	}
-	if len(servers) > 0 {
-		openapi.Servers = servers
+	if outputFormat == formatJSON && *outputFile == DefaultOutput {
+		*outputFile = strings.TrimSuffix(DefaultOutput, filepath.Ext(DefaultOutput)) + ".json"
 	}
-	return openapi
-}
-
-func hasPathParam(params []Parameter, name string) bool {
-	for _, p := range params {
-		if p.In == "path" && p.Name == name {
-			return true
-		}
+	if *specVersion != specVersion3 && *specVersion != specVersion2 {
+		fmt.Println("Error:", fmt.Errorf("unknown spec version %q (want %s or %s)", *specVersion, specVersion3, specVersion2))
+		os.Exit(1)
 	}
-	return false
-}
-
-func safeJSON(text string) any {
-	var out any
-	if err := json.Unmarshal([]byte(text), &out); err == nil {
-		return out
+	log, err := newLogger(*logFormat, *noProgress)
+	if err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
 	}
-	return text
-}
-
-func collectBruFiles(dir string) ([]string, error) {
-	results := []string{}
-	walkFn := func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
-			return err
-		}
-		if d.IsDir() {
-			return nil
-		}
-		if strings.HasSuffix(d.Name(), ".bru") {
-			results = append(results, path)
-		}
-		return nil
+	if err := checkOutputFile(*outputFile, *mkdir); err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(exitOutputUnwritable)
 	}
-	if err := filepath.WalkDir(dir, walkFn); err != nil {
-		return nil, err
+	conv := &converter{
+		inputDir:    *inputDir,
+		outputFile:  *outputFile,
+		opts:        opts,
+		strict:      *strict,
+		specVersion: *specVersion,
+		format:      outputFormat,
+		log:         log,
 	}
-	return results, nil
-}
 
-func splitURL(raw string) (string, string) {
-	if strings.TrimSpace(raw) == "" {
-		return "/", ""
-	}
-	trimmed := strings.TrimSpace(raw)
-	if strings.HasPrefix(trimmed, "{{") && strings.Contains(trimmed, "}}") {
-		endIdx := strings.Index(trimmed, "}}")
-		base := trimmed[:endIdx+2]
-		rest := trimmed[endIdx+2:]
-		if rest == "" {
-			rest = "/"
+	spec, err := conv.generate()
+	if err != nil {
+		fmt.Println("Error:", err)
+		if !*watchMode {
+			os.Exit(1)
 		}
-		return rest, base
 	}
 
-	if strings.HasPrefix(trimmed, "http://") || strings.HasPrefix(trimmed, "https://") {
-		if u, err := url.Parse(trimmed); err == nil {
-			pathName := u.Path
-			if pathName == "" {
-				pathName = "/"
-			}
-			return pathName, u.Scheme + "://" + u.Host
-		}
-		return "/", ""
+	if *serveAddr == "" && !*watchMode {
+		return
 	}
 
-	if strings.HasPrefix(trimmed, "/") {
-		return trimmed, ""
+	var srv *specServer
+	if *serveAddr != "" {
+		srv = newSpecServer(spec)
+		go func() {
+			fmt.Printf("🌐 Serving Swagger UI at http://%s/\n", displayAddr(*serveAddr))
+			if err := http.ListenAndServe(*serveAddr, srv); err != nil {
+				fmt.Println("Error serving spec:", err)
+				os.Exit(1)
+			}
+		}()
+	}
+	if !*watchMode {
+		select {}
 	}
-	return "/" + trimmed, ""
+
+	fmt.Println("👀 Watching", *inputDir, "for changes...")
+	watchCollection(*inputDir, func() {
+		log.rebuilding()
+		spec, err := conv.generate()
+		if err != nil {
+			fmt.Println("Error:", err)
+			return
+		}
+		if srv != nil {
+			srv.setSpec(spec)
+		}
+	})
 }
 
-func normalizePathParams(pathName string) string {
-	re := regexp.MustCompile(`:([A-Za-z0-9_]+)`)
-	return re.ReplaceAllString(pathName, "{$1}")
+// converter holds everything needed to (re)generate the spec.
+type converter struct {
+	inputDir   string
+	outputFile string
+	opts       bruno2openapi.Options
+	strict     bool
+	// specVersion is specVersion3 or specVersion2.
+	specVersion string
+	// format is formatYAML or formatJSON.
+	format string
+	log    *logger
 }
 
-func extractPathParams(pathName string) []string {
-	matches := pathParamRegex.FindAllStringSubmatch(pathName, -1)
-	out := []string{}
-	for _, m := range matches {
-		if len(m) > 1 {
-			out = append(out, m[1])
+// generate converts the collection, writes the YAML spec to the output
+// file and returns it. With strict set, .bru syntax problems and
+// validation findings abort the conversion before anything is written.
+func (c *converter) generate() ([]byte, error) {
+	parseStart := time.Now()
+	requests, warnings, err := loadCollection(c.inputDir, c.log.progressFunc())
+	if err != nil {
+		return nil, err
+	}
+	parseTime := time.Since(parseStart)
+	syntaxErrors := 0
+	for _, w := range warnings {
+		c.log.warning(w)
+		if w.Code == bruno2openapi.WarnSyntax {
+			syntaxErrors++
 		}
 	}
-	return out
-}
-
-func buildRequestBody(req Request) *RequestBody {
-	if strings.TrimSpace(req.Body) == "" {
-		return nil
+	if c.strict && syntaxErrors > 0 {
+		return nil, fmt.Errorf("found %d .bru syntax problem(s) (--strict)", syntaxErrors)
 	}
 
-	contentType := "application/json"
-	if req.BodyType == "text" {
-		contentType = "text/plain"
+	buildStart := time.Now()
+	openapi, err := bruno2openapi.Build(requests, c.opts)
+	if err != nil {
+		return nil, err
 	}
-	if req.BodyType == "graphql" {
-		contentType = "application/graphql"
+	for _, w := range openapi.Warnings {
+		c.log.warning(w)
 	}
-	if v, ok := req.Headers["Content-Type"]; ok {
-		contentType = v
+	problems := bruno2openapi.Validate(openapi)
+	for _, w := range problems {
+		c.log.warning(w)
 	}
-	if v, ok := req.Headers["content-type"]; ok {
-		contentType = v
+	if c.strict && len(problems) > 0 {
+		return nil, fmt.Errorf("spec validation found %d problem(s) (--strict)", len(problems))
 	}
-
-	var media MediaType
-	if strings.Contains(strings.ToLower(contentType), "json") {
-		parsed := safeJSON(req.Body)
-		media = MediaType{
-			Schema:  &MediaSchema{Type: "object"},
-			Example: parsed,
+	var out []byte
+	switch {
+	case c.specVersion == specVersion2:
+		swagger := bruno2openapi.ToSwagger(openapi)
+		for _, w := range swagger.Warnings {
+			c.log.warning(w)
 		}
-	} else {
-		media = MediaType{
-			Schema:  &MediaSchema{Type: "string"},
-			Example: req.Body,
+		if c.format == formatJSON {
+			out, err = bruno2openapi.MarshalSwaggerJSON(swagger)
+		} else {
+			out, err = bruno2openapi.MarshalSwaggerYAML(swagger)
 		}
+	case c.format == formatJSON:
+		out, err = bruno2openapi.MarshalJSON(openapi)
+	default:
+		out, err = bruno2openapi.MarshalYAML(openapi)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("generating %s: %w", strings.ToUpper(c.format), err)
 	}
+	buildTime := time.Since(buildStart)
 
-	return &RequestBody{
-		Required: true,
-		Content: map[string]MediaType{
-			contentType: media,
-		},
+	if err := os.WriteFile(c.outputFile, out, 0644); err != nil {
+		return nil, fmt.Errorf("writing output: %w", err)
 	}
+	c.log.summary(c.outputFile, countRequests(requests), countExcluded(requests), parseTime, buildTime)
+	return out, nil
 }
 
-func main() {
-	inputDir := flag.String("i", "", "Path ke folder Bruno collection")
-	outputFile := flag.String("o", DefaultOutput, "Path output OpenAPI YAML")
-	flag.Parse()
+// runLint implements `bruno-to-openapi lint`. It reports warnings without
+// writing a spec and returns the process exit code.
+func runLint(args []string) int {
+	fset := flag.NewFlagSet("lint", flag.ExitOnError)
+	inputDir := fset.String("i", "", "Path ke folder Bruno collection")
+	failOn := fset.String("fail-on", "", "Daftar kode warning (dipisah koma, atau \"any\") yang membuat lint gagal")
+	fset.Parse(args)
 
 	if strings.TrimSpace(*inputDir) == "" {
 		fmt.Println("Error: input directory wajib diisi dengan -i <path>")
-		os.Exit(1)
+		return 1
 	}
 
-	files, err := collectBruFiles(*inputDir)
+	requests, warnings, err := loadCollection(*inputDir, nil)
 	if err != nil {
-		fmt.Println("Error reading Bruno directory:", err)
-		os.Exit(1)
+		fmt.Println("Error:", err)
+		return 1
+	}
+	openapi, err := bruno2openapi.Build(requests, bruno2openapi.NewDefaultOptions())
+	if err != nil {
+		fmt.Println("Error:", err)
+		return 1
+	}
+	warnings = append(warnings, openapi.Warnings...)
+	warnings = append(warnings, bruno2openapi.Validate(openapi)...)
+
+	// Probable secrets are listed first so they are not lost in the noise.
+	sort.SliceStable(warnings, func(i, j int) bool {
+		return warnings[i].Code == bruno2openapi.WarnPossibleSecret && warnings[j].Code != bruno2openapi.WarnPossibleSecret
+	})
+	secrets := 0
+	for _, w := range warnings {
+		if w.Code == bruno2openapi.WarnPossibleSecret {
+			if secrets == 0 {
+				fmt.Println("🔑 Possible secrets committed in the collection:")
+			}
+			secrets++
+		}
+		fmt.Println(" ", w)
 	}
 
-	requests := []Request{}
-	for _, file := range files {
-		content, err := os.ReadFile(file)
-		if err != nil {
-			fmt.Println("Error reading file:", file, err)
-			os.Exit(1)
+	ignored := countExcluded(requests)
+	if ignored > 0 {
+		fmt.Println("🙈 Requests excluded from the spec (meta ignore / no-docs tag):")
+		for _, req := range requests {
+			if req.Excluded() && isRequest(req) {
+				fmt.Printf("  %s (%s)\n", req.File, req.Name)
+			}
 		}
-		parsed := parseBru(string(content))
-		rel, _ := filepath.Rel(*inputDir, filepath.Dir(file))
-		rel = filepath.ToSlash(rel)
-		if rel != "." {
-			parsed.Tag = rel
+	}
+
+	failCodes := map[string]bool{}
+	for _, code := range splitList(*failOn) {
+		failCodes[code] = true
+	}
+	failed := 0
+	for _, w := range warnings {
+		if failCodes["any"] || failCodes[w.Code] {
+			failed++
 		}
-		requests = append(requests, parsed)
 	}
 
-	openapi := buildOpenAPI(requests)
-	yamlOut, err := yaml.Marshal(openapi)
+	fmt.Printf("%d warning(s), %d possible secret(s), %d ignored request(s)\n", len(warnings), secrets, ignored)
+	if failed > 0 {
+		fmt.Printf("❌ lint failed: %d warning(s) match --fail-on\n", failed)
+		return 1
+	}
+	return 0
+}
+
+// environmentVariables loads the named environment of the collection in
+// inputDir as the variables used to resolve placeholders.
+func environmentVariables(inputDir, name string) (*bruno2openapi.Variables, error) {
+	envs, err := bruno2openapi.LoadEnvironments(os.DirFS(inputDir), ".")
 	if err != nil {
-		fmt.Println("Error generating YAML:", err)
-		os.Exit(1)
+		return nil, err
 	}
+	env, err := bruno2openapi.FindEnvironment(envs, name)
+	if err != nil {
+		return nil, err
+	}
+	vars := bruno2openapi.NewVariables()
+	vars.SelectEnvironment(env.Name)
+	vars.AddEnvironment(env)
+	return vars, nil
+}
 
-	if err := os.WriteFile(*outputFile, yamlOut, 0644); err != nil {
-		fmt.Println("Error writing output:", err)
-		os.Exit(1)
+// optionFlags maps the Options fields a *bruno2openapi.ConflictError can
+// name to the flags that set them.
+var optionFlags = map[string]string{
+	"FoldProbeMethods": "--fold-probe-methods",
+	"ExtraMethods":     "--extra-methods",
+	"BasePathMode":     "--base-path-mode",
+	"Environments":     "--env-servers",
+	"TagStrategy":      "--tag-strategy",
+	"TagDepth":         "--tag-depth",
+}
+
+// flagError rewords an option conflict in terms of the command line flags
+// that set the two options; other errors are returned as they are.
+func flagError(err error) error {
+	var conflict *bruno2openapi.ConflictError
+	if !errors.As(err, &conflict) {
+		return err
+	}
+	option, other := optionFlags[conflict.Option], optionFlags[conflict.Other]
+	if option == "" || other == "" {
+		return err
+	}
+	return fmt.Errorf("flags %s and %s conflict: %s", option, other, conflict.Reason)
+}
+
+// isRequest reports whether req came from a request file, rather than
+// being an entry CollectRequests adds for a skipped or folder file.
+func isRequest(req bruno2openapi.Request) bool {
+	return !req.Skipped && !req.FolderFile
+}
+
+func countRequests(requests []bruno2openapi.Request) int {
+	n := 0
+	for _, req := range requests {
+		if isRequest(req) {
+			n++
+		}
+	}
+	return n
+}
+
+func countExcluded(requests []bruno2openapi.Request) int {
+	n := 0
+	for _, req := range requests {
+		if req.Excluded() && isRequest(req) {
+			n++
+		}
 	}
+	return n
+}
+
+// varFlag collects repeated --var name=value flags.
+type varFlag map[string]string
+
+func (f varFlag) String() string { return "" }
 
-	fmt.Println("✅ OpenAPI generated:", *outputFile)
+func (f varFlag) Set(value string) error {
+	name, val, ok := strings.Cut(value, "=")
+	if name = strings.TrimSpace(name); !ok || name == "" {
+		return fmt.Errorf("expected name=value, got %q", value)
+	}
+	f[name] = val
+	return nil
+}
+
+// splitList parses a comma-separated flag value, dropping empty items.
+func splitList(value string) []string {
+	items := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
 }
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// exitOutputUnwritable is the exit code used when the output target fails
+// validation, so scripts can tell it apart from conversion errors.
+const exitOutputUnwritable = 3
+
+// checkOutputFile verifies, before any parsing work, that path can be
+// written as a file: it is not a directory, its parent exists (or is
+// created when mkdir is set) and the process may write there.
+func checkOutputFile(path string, mkdir bool) error {
+	if info, err := os.Stat(path); err == nil {
+		if info.IsDir() {
+			return fmt.Errorf("output path %s is a directory; pass a file name such as %s", path, filepath.Join(path, "openapi.yml"))
+		}
+		f, err := os.OpenFile(path, os.O_WRONLY, 0)
+		if err != nil {
+			return fmt.Errorf("output file %s is not writable: %w", path, err)
+		}
+		return f.Close()
+	}
+	return checkOutputDir(filepath.Dir(path), mkdir)
+}
+
+// checkOutputDir verifies that dir exists (creating it when mkdir is set)
+// and that files can be created inside it.
+func checkOutputDir(dir string, mkdir bool) error {
+	info, err := os.Stat(dir)
+	switch {
+	case os.IsNotExist(err) && mkdir:
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("creating output directory %s: %w", dir, err)
+		}
+	case os.IsNotExist(err):
+		return fmt.Errorf("output directory %s does not exist (use --mkdir to create it)", dir)
+	case err != nil:
+		return fmt.Errorf("checking output directory %s: %w", dir, err)
+	case !info.IsDir():
+		return fmt.Errorf("output directory %s is not a directory", dir)
+	}
+
+	probe, err := os.CreateTemp(dir, ".bruno-to-openapi-*")
+	if err != nil {
+		return fmt.Errorf("output directory %s is not writable: %w", dir, err)
+	}
+	probe.Close()
+	return os.Remove(probe.Name())
+}
+
+// resolveFormat returns the output format: the -f value when given,
+// otherwise json for a .json output file and yaml for anything else.
+func resolveFormat(format, path string) (string, error) {
+	switch strings.ToLower(format) {
+	case "":
+		if strings.EqualFold(filepath.Ext(path), ".json") {
+			return formatJSON, nil
+		}
+		return formatYAML, nil
+	case formatYAML, "yml":
+		return formatYAML, nil
+	case formatJSON:
+		return formatJSON, nil
+	}
+	return "", fmt.Errorf("unknown output format %q (want %s or %s)", format, formatYAML, formatJSON)
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"bruno-openapi/bruno2openapi"
+)
+
+func TestCheckOutputFile(t *testing.T) {
+	dir := t.TempDir()
+
+	if err := checkOutputFile(dir, false); err == nil {
+		t.Error("expected an error for a directory target")
+	}
+	missing := filepath.Join(dir, "a", "b", "openapi.yml")
+	if err := checkOutputFile(missing, false); err == nil {
+		t.Error("expected an error for a missing parent without mkdir")
+	}
+	if err := checkOutputFile(missing, true); err != nil {
+		t.Errorf("mkdir should create the parent: %v", err)
+	}
+	if err := checkOutputFile(filepath.Join(dir, "openapi.yml"), false); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestResolveFormat(t *testing.T) {
+	tests := []struct {
+		format, path, want string
+	}{
+		{"", "openapi.yml", formatYAML},
+		{"", "out/spec.JSON", formatJSON},
+		{"json", "openapi.yml", formatJSON},
+		{"yml", "spec.json", formatYAML},
+	}
+	for _, tt := range tests {
+		if got, err := resolveFormat(tt.format, tt.path); err != nil || got != tt.want {
+			t.Errorf("resolveFormat(%q, %q) = %q, %v; want %q", tt.format, tt.path, got, err, tt.want)
+		}
+	}
+	if _, err := resolveFormat("toml", "openapi.yml"); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
+
+func TestFlagErrorNamesFlags(t *testing.T) {
+	opts := bruno2openapi.NewDefaultOptions()
+	opts.FoldProbeMethods = true
+	opts.ExtraMethods = []string{"options"}
+	err := flagError(opts.Validate())
+	if err == nil || !strings.Contains(err.Error(), "--fold-probe-methods") || !strings.Contains(err.Error(), "--extra-methods") {
+		t.Errorf("got %v, want both flags named", err)
+	}
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	watchInterval = 300 * time.Millisecond
+	watchDebounce = 500 * time.Millisecond
+)
+
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// snapshotBruFiles records the modification time and size of every .bru
+// file below dir. Unreadable entries are skipped so a file that is being
+// saved does not abort the watcher.
+func snapshotBruFiles(dir string) map[string]fileStamp {
+	snap := map[string]fileStamp{}
+	filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(d.Name(), ".bru") {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		snap[path] = fileStamp{modTime: info.ModTime(), size: info.Size()}
+		return nil
+	})
+	return snap
+}
+
+func sameSnapshot(a, b map[string]fileStamp) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for path, stamp := range a {
+		if other, ok := b[path]; !ok || other != stamp {
+			return false
+		}
+	}
+	return true
+}
+
+// watchCollection polls dir for created, modified, deleted or renamed .bru
+// files (including ones in new subfolders) and calls rebuild once the
+// collection has been quiet for watchDebounce. It never returns.
+func watchCollection(dir string, rebuild func()) {
+	w := newCollectionWatcher(dir)
+	for {
+		time.Sleep(watchInterval)
+		if w.poll(time.Now()) {
+			rebuild()
+		}
+	}
+}
+
+// collectionWatcher compares successive snapshots of a collection.
+type collectionWatcher struct {
+	dir       string
+	last      map[string]fileStamp
+	changedAt time.Time
+}
+
+func newCollectionWatcher(dir string) *collectionWatcher {
+	return &collectionWatcher{dir: dir, last: snapshotBruFiles(dir)}
+}
+
+// poll takes a snapshot at now and reports whether a rebuild is due: the
+// collection changed and has since been quiet for watchDebounce.
+func (w *collectionWatcher) poll(now time.Time) bool {
+	current := snapshotBruFiles(w.dir)
+	if !sameSnapshot(w.last, current) {
+		w.last = current
+		w.changedAt = now
+		return false
+	}
+	if !w.changedAt.IsZero() && now.Sub(w.changedAt) >= watchDebounce {
+		w.changedAt = time.Time{}
+		return true
+	}
+	return false
+}
+
+// specServer serves the most recently generated spec at /openapi.yml and
+// a Swagger UI page pointing at it on /.
+type specServer struct {
+	mu   sync.RWMutex
+	spec []byte
+}
+
+func newSpecServer(spec []byte) *specServer {
+	return &specServer{spec: spec}
+}
+
+func (s *specServer) setSpec(spec []byte) {
+	s.mu.Lock()
+	s.spec = spec
+	s.mu.Unlock()
+}
+
+func (s *specServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/openapi.yml":
+		s.mu.RLock()
+		spec := s.spec
+		s.mu.RUnlock()
+		w.Header().Set("Content-Type", "application/yaml")
+		w.Header().Set("Cache-Control", "no-store")
+		w.Write(spec)
+	case "/":
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, swaggerUIPage)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// displayAddr turns a listen address such as ":8080" into something that
+// can be pasted into a browser.
+func displayAddr(addr string) string {
+	if strings.HasPrefix(addr, ":") {
+		return "localhost" + addr
+	}
+	return addr
+}
+
+const swaggerUIPage = `<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="utf-8">
+  <title>OpenAPI preview</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+  <script>
+    window.ui = SwaggerUIBundle({ url: "/openapi.yml", dom_id: "#swagger-ui" });
+  </script>
+</body>
+</html>
+`
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCollectionWatcherRebuildsOnce(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "users", "list.bru")
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(file, []byte("get {\n  url: /users\n}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	w := newCollectionWatcher(dir)
+	start := time.Now()
+	rebuilds := 0
+	poll := func(n int) {
+		if w.poll(start.Add(time.Duration(n) * watchInterval)) {
+			rebuilds++
+		}
+	}
+
+	poll(1)
+	if rebuilds != 0 {
+		t.Fatalf("rebuilt %d time(s) without a change", rebuilds)
+	}
+	if err := os.WriteFile(file, []byte("get {\n  url: /users?page=1\n}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for n := 2; n <= 10; n++ {
+		poll(n)
+	}
+	if rebuilds != 1 {
+		t.Errorf("one change gave %d rebuild(s), want 1", rebuilds)
+	}
+}
+
+func TestSpecServer(t *testing.T) {
+	s := newSpecServer([]byte("openapi: 3.0.3\n"))
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+		return rec
+	}
+
+	rec := get("/openapi.yml")
+	if rec.Code != 200 || rec.Header().Get("Content-Type") != "application/yaml" || rec.Body.String() != "openapi: 3.0.3\n" {
+		t.Errorf("got %d %q: %q", rec.Code, rec.Header().Get("Content-Type"), rec.Body.String())
+	}
+	s.setSpec([]byte("openapi: 3.1.0\n"))
+	if body := get("/openapi.yml").Body.String(); body != "openapi: 3.1.0\n" {
+		t.Errorf("rebuilt spec not served: %q", body)
+	}
+	if rec := get("/"); rec.Header().Get("Content-Type") != "text/html; charset=utf-8" {
+		t.Errorf("Swagger UI page served as %q", rec.Header().Get("Content-Type"))
+	}
+	if rec := get("/missing"); rec.Code != 404 {
+		t.Errorf("unknown path: got %d", rec.Code)
+	}
+}